@@ -0,0 +1,145 @@
+package dnspod
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// AgentService handles communication with the agent (代理) related methods of the dnspod API,
+// available to agent accounts managing the accounts of their clients.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/agents.html
+type AgentService struct {
+	client *Client
+}
+
+// AgentClient is an account managed by an agent.
+type AgentClient struct {
+	UserID    string `json:"user_id,omitempty"`
+	Email     string `json:"email,omitempty"`
+	RealName  string `json:"real_name,omitempty"`
+	Nick      string `json:"nick,omitempty"`
+	Telephone string `json:"telephone,omitempty"`
+	UserType  string `json:"user_type,omitempty"` // "personal" or "enterprise"
+	Status    string `json:"status,omitempty"`
+	CreatedOn string `json:"created_on,omitempty"`
+}
+
+// AgentClientQuery filters and pages AgentService.ListClients.
+type AgentClientQuery struct {
+	Keyword     string
+	CurrentPage int
+	PageSize    int
+}
+
+// PaginationAgentClientList is a page of the clients of an agent.
+type PaginationAgentClientList struct {
+	CurrentPage int           `json:"currentPage"`
+	PageSize    int           `json:"pageSize"`
+	Total       int           `json:"total"`
+	List        []AgentClient `json:"list"`
+}
+
+// NewAgentClient holds the attributes of a client account created by AgentService.CreateClient.
+type NewAgentClient struct {
+	Email     string
+	Password  string
+	RealName  string
+	Telephone string
+	UserType  string // "personal" unless set
+}
+
+type agentClientsWrapper struct {
+	Status  Status        `json:"status"`
+	Info    agentInfo     `json:"info"`
+	Clients []AgentClient `json:"clients"`
+}
+
+type agentInfo struct {
+	Total string `json:"client_total"`
+}
+
+type agentClientWrapper struct {
+	Status Status      `json:"status"`
+	Client AgentClient `json:"client"`
+}
+
+// agentAction generates the resource path for given agent action.
+func agentAction(action string) string {
+	return fmt.Sprintf("Agent.Client.%s", action)
+}
+
+// ListClients lists the client accounts of the agent.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/agents.html#agent-client-list
+func (s *AgentService) ListClients(query AgentClientQuery) (PaginationAgentClientList, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	if query.Keyword != "" {
+		payload.Set("keyword", query.Keyword)
+	}
+	if query.PageSize != 0 {
+		payload.Set("offset", strconv.Itoa(query.CurrentPage))
+		payload.Set("length", strconv.Itoa(query.PageSize))
+	}
+
+	wrapper := agentClientsWrapper{}
+	res, err := s.client.post(agentAction("List"), payload, &wrapper)
+	if err != nil {
+		return PaginationAgentClientList{}, res, err
+	}
+
+	total, err := strconv.Atoi(wrapper.Info.Total)
+	if err != nil {
+		total = len(wrapper.Clients)
+	}
+	return PaginationAgentClientList{
+		CurrentPage: query.CurrentPage,
+		PageSize:    query.PageSize,
+		Total:       total,
+		List:        wrapper.Clients,
+	}, res, nil
+}
+
+// CreateClient creates a client account managed by the agent.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/agents.html#agent-client-create
+func (s *AgentService) CreateClient(c NewAgentClient) (AgentClient, *Response, error) {
+	userType := c.UserType
+	if userType == "" {
+		userType = "personal"
+	}
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("email", c.Email)
+	payload.Set("password", c.Password)
+	payload.Set("user_type", userType)
+	if c.RealName != "" {
+		payload.Set("real_name", c.RealName)
+	}
+	if c.Telephone != "" {
+		payload.Set("telephone", c.Telephone)
+	}
+
+	wrapper := agentClientWrapper{}
+	res, err := s.client.post(agentAction("Create"), payload, &wrapper)
+	if err != nil {
+		return AgentClient{}, res, err
+	}
+	return wrapper.Client, res, nil
+}
+
+// ForClient returns a client acting on behalf of a client account of the agent,
+// see Client.ForUser.
+func (s *AgentService) ForClient(userID string) *Client {
+	return s.client.ForUser(userID)
+}
+
+// ForUser returns a copy of the client acting on behalf of the account userID, e.g. a client
+// of an agent account, by setting the user_id common parameter. The copy shares the HTTP
+// client, middlewares, instrumentation and logging of c, which is left unchanged.
+func (c *Client) ForUser(userID string) *Client {
+	scoped := *c
+	scoped.CommonParams.UserID = userID
+	scoped.Middlewares = append([]Middleware(nil), c.Middlewares...)
+	scoped.initServices()
+	return &scoped
+}
@@ -0,0 +1,127 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAgentService_ListClients(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Agent.Client.List", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testFormValues(t, r, values{"login_token": "dnspod login token", "keyword": "example", "offset": "0", "length": "20"})
+		fmt.Fprint(w, `{"status": {"code":"1"},"info":{"client_total":21},"clients":[{"user_id":1001,"email":"ops@example.com","status":"enabled"}]}`)
+	})
+
+	clients, _, err := client.Agent.ListClients(AgentClientQuery{Keyword: "example", PageSize: 20})
+	if err != nil {
+		t.Fatalf("Agent.ListClients returned error: %v", err)
+	}
+
+	want := PaginationAgentClientList{PageSize: 20, Total: 21, List: []AgentClient{{UserID: "1001", Email: "ops@example.com", Status: "enabled"}}}
+	if !reflect.DeepEqual(clients, want) {
+		t.Errorf("Agent.ListClients returned %+v, want %+v", clients, want)
+	}
+}
+
+func TestAgentService_CreateClient(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Agent.Client.Create", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{
+			"login_token": "dnspod login token",
+			"email":       "ops@example.com",
+			"password":    "secret",
+			"user_type":   "personal",
+			"telephone":   "13800000000",
+		})
+		fmt.Fprint(w, `{"status": {"code":"1"},"client":{"user_id":"1001","email":"ops@example.com"}}`)
+	})
+
+	created, _, err := client.Agent.CreateClient(NewAgentClient{Email: "ops@example.com", Password: "secret", Telephone: "13800000000"})
+	if err != nil {
+		t.Fatalf("Agent.CreateClient returned error: %v", err)
+	}
+	if want := (AgentClient{UserID: "1001", Email: "ops@example.com"}); created != want {
+		t.Errorf("Agent.CreateClient returned %+v, want %+v", created, want)
+	}
+}
+
+func TestClient_ForUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	client.Use(func(next Handler) Handler {
+		return HandlerFunc(func(req *Request) (*Result, error) {
+			calls = append(calls, req.Action)
+			return next.Handle(req)
+		})
+	})
+	mux.HandleFunc("/Domain.List", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "user_id": "1001"})
+		fmt.Fprint(w, `{"status": {"code":"1"},"info":{"domain_total":1},"domains":[{"id":1,"name":"example.com"}]}`)
+	})
+
+	scoped := client.Agent.ForClient("1001")
+	if _, _, err := scoped.Domains.List(DomainQuery{}); err != nil {
+		t.Fatalf("Domains.List on behalf of a client returned error: %v", err)
+	}
+
+	if client.CommonParams.UserID != "" {
+		t.Errorf("ForUser changed the CommonParams of the client: %+v", client.CommonParams)
+	}
+	if scoped.Domains.client != scoped || scoped.Users.client != scoped || scoped.Agent.client != scoped {
+		t.Errorf("ForUser returned services of another client")
+	}
+	if len(calls) != 1 || calls[0] != "Domain.List" {
+		t.Errorf("ForUser did not keep the middlewares: %v", calls)
+	}
+
+	scoped.Use(func(next Handler) Handler { return next })
+	if len(client.Middlewares) != 1 {
+		t.Errorf("Use on the scoped client changed the middlewares of the client")
+	}
+}
+
+func TestClient_ForUser_services(t *testing.T) {
+	c := NewClient(CommonParams{LoginToken: "dnspod login token"})
+	scoped := c.ForUser("1001")
+
+	// Every service of NewClient is set up for the scoped client, bound to it.
+	clientType := reflect.TypeOf(c)
+	v, sv := reflect.ValueOf(c).Elem(), reflect.ValueOf(scoped).Elem()
+	services := 0
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Type.Kind() != reflect.Ptr || field.Type.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		back, ok := field.Type.Elem().FieldByName("client")
+		if !ok || back.Type != clientType {
+			continue
+		}
+		services++
+		if v.Field(i).IsNil() {
+			t.Fatalf("NewClient left %s unset", field.Name)
+		}
+		if v.Field(i).Elem().FieldByIndex(back.Index).Pointer() != reflect.ValueOf(c).Pointer() {
+			t.Errorf("NewClient bound %s to another client", field.Name)
+		}
+		if sv.Field(i).IsNil() {
+			t.Errorf("ForUser left %s unset", field.Name)
+			continue
+		}
+		if sv.Field(i).Elem().FieldByIndex(back.Index).Pointer() != reflect.ValueOf(scoped).Pointer() {
+			t.Errorf("ForUser bound %s to another client", field.Name)
+		}
+	}
+	if services == 0 {
+		t.Errorf("Client has no service")
+	}
+}
@@ -0,0 +1,196 @@
+package dnspod
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Periods of the resolution analytics.
+const (
+	AnalyticsToday      = "today"
+	AnalyticsYesterday  = "yesterday"
+	AnalyticsLast7Days  = "last_7_days"
+	AnalyticsLast30Days = "last_30_days"
+	AnalyticsCustom     = "custom" // from AnalyticsQuery.Start to AnalyticsQuery.End
+)
+
+// Buckets the analytics points can be aggregated into.
+const (
+	BucketHourly = time.Hour
+	BucketDaily  = 24 * time.Hour
+)
+
+// analyticsLocation is the time zone of the analytics timestamps.
+var analyticsLocation = time.FixedZone("CST", 8*60*60)
+
+func init() {
+	registerReadOnly("Domain.Analytics", "Subdomain.Analytics")
+}
+
+// AnalyticsQuery selects the resolution analytics of a domain or of one of its subdomains.
+type AnalyticsQuery struct {
+	DomainID  string
+	SubDomain string    // only used by SubdomainAnalytics
+	Period    string    // defaults to AnalyticsToday
+	Start     time.Time // only used by AnalyticsCustom
+	End       time.Time // only used by AnalyticsCustom
+}
+
+// AnalyticsPoint is the number of queries resolved from Time on.
+type AnalyticsPoint struct {
+	Time  time.Time `json:"time"`
+	Count int       `json:"count"`
+}
+
+// AnalyticsSeries is a time series of resolution counts, ordered by time.
+type AnalyticsSeries struct {
+	Domain    string           `json:"domain,omitempty"`
+	SubDomain string           `json:"sub_domain,omitempty"`
+	Period    string           `json:"period,omitempty"`
+	Total     int              `json:"total"`
+	Points    []AnalyticsPoint `json:"points"`
+}
+
+type analyticsPoint struct {
+	Time  string `json:"time"`
+	Count int    `json:"num"`
+}
+
+type analyticsInfo struct {
+	Domain    string `json:"domain"`
+	SubDomain string `json:"sub_domain"`
+	Total     int    `json:"total"`
+}
+
+type analyticsWrapper struct {
+	Status Status           `json:"status"`
+	Info   analyticsInfo    `json:"info"`
+	Data   []analyticsPoint `json:"data"`
+}
+
+// Analytics fetches the number of queries resolved for a domain.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/domains.html#domain-analytics
+func (s *DomainsService) Analytics(query AnalyticsQuery) (AnalyticsSeries, *Response, error) {
+	return s.analytics("Domain.Analytics", query)
+}
+
+// SubdomainAnalytics fetches the number of queries resolved for a subdomain.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/domains.html#subdomain-analytics
+func (s *DomainsService) SubdomainAnalytics(query AnalyticsQuery) (AnalyticsSeries, *Response, error) {
+	if query.SubDomain == "" {
+		return AnalyticsSeries{}, nil, fmt.Errorf("subdomain analytics require a subdomain")
+	}
+	return s.analytics("Subdomain.Analytics", query)
+}
+
+func (s *DomainsService) analytics(action string, query AnalyticsQuery) (AnalyticsSeries, *Response, error) {
+	period := query.Period
+	if period == "" {
+		period = AnalyticsToday
+	}
+
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", query.DomainID)
+	if action == "Subdomain.Analytics" {
+		payload.Set("subdomain", query.SubDomain)
+	}
+	payload.Set("period", period)
+	if period == AnalyticsCustom {
+		if query.Start.IsZero() || query.End.Before(query.Start) {
+			return AnalyticsSeries{}, nil, fmt.Errorf("custom analytics period requires a start before the end")
+		}
+		payload.Set("start_date", query.Start.In(analyticsLocation).Format("2006-01-02"))
+		payload.Set("end_date", query.End.In(analyticsLocation).Format("2006-01-02"))
+	}
+
+	wrapper := analyticsWrapper{}
+	res, err := s.client.post(action, payload, &wrapper)
+	if err != nil {
+		return AnalyticsSeries{}, res, err
+	}
+
+	series := AnalyticsSeries{
+		Domain:    wrapper.Info.Domain,
+		SubDomain: wrapper.Info.SubDomain,
+		Period:    period,
+		Total:     wrapper.Info.Total,
+		Points:    make([]AnalyticsPoint, 0, len(wrapper.Data)),
+	}
+	sum := 0
+	for _, p := range wrapper.Data {
+		t, err := parseAnalyticsTime(p.Time)
+		if err != nil {
+			return AnalyticsSeries{}, res, err
+		}
+		series.Points = append(series.Points, AnalyticsPoint{Time: t, Count: p.Count})
+		sum += p.Count
+	}
+	sort.SliceStable(series.Points, func(i, j int) bool { return series.Points[i].Time.Before(series.Points[j].Time) })
+	if series.Total == 0 {
+		series.Total = sum
+	}
+	return series, res, nil
+}
+
+// parseAnalyticsTime parses the timestamps of the analytics points, either
+// Unix times or dates with an optional time of the day. Compact dates, such as
+// the hourly "2024010112", come before Unix times, of the same length: a number
+// reading as a date from 1970 to 2099 is taken as a date.
+func parseAnalyticsTime(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02 15", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, analyticsLocation); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"2006010215", "20060102"} {
+		if t, err := time.ParseInLocation(layout, s, analyticsLocation); err == nil && t.Year() >= 1970 && t.Year() < 2100 {
+			return t, nil
+		}
+	}
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil && len(s) >= 10 && unix > 1e9 {
+		return time.Unix(unix, 0).In(analyticsLocation), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid analytics time %q", s)
+}
+
+// Aggregate sums the points of the series into buckets of the given size,
+// usually BucketHourly or BucketDaily. Buckets are aligned on the time zone
+// of the points, so that daily buckets start at midnight; sizes above a day are treated as daily.
+func (s AnalyticsSeries) Aggregate(bucket time.Duration) []AnalyticsPoint {
+	return AggregateAnalytics(s.Points, bucket)
+}
+
+// AggregateAnalytics sums points into buckets of the given size, see AnalyticsSeries.Aggregate.
+func AggregateAnalytics(points []AnalyticsPoint, bucket time.Duration) []AnalyticsPoint {
+	if bucket <= 0 {
+		return append([]AnalyticsPoint(nil), points...)
+	}
+
+	var buckets []AnalyticsPoint
+	index := map[time.Time]int{}
+	for _, p := range points {
+		start := bucketStart(p.Time, bucket)
+		i, ok := index[start]
+		if !ok {
+			i = len(buckets)
+			index[start] = i
+			buckets = append(buckets, AnalyticsPoint{Time: start})
+		}
+		buckets[i].Count += p.Count
+	}
+	sort.SliceStable(buckets, func(i, j int) bool { return buckets[i].Time.Before(buckets[j].Time) })
+	return buckets
+}
+
+// bucketStart returns the start of the bucket holding t, counting from midnight in the location of t.
+func bucketStart(t time.Time, bucket time.Duration) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if bucket >= BucketDaily {
+		return midnight
+	}
+	return midnight.Add(t.Sub(midnight) / bucket * bucket)
+}
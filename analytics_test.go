@@ -0,0 +1,104 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDomainsService_SubdomainAnalytics(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Subdomain.Analytics", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testFormValues(t, r, values{
+			"login_token": "dnspod login token",
+			"domain_id":   "1",
+			"subdomain":   "www",
+			"period":      AnalyticsCustom,
+			"start_date":  "2015-01-17",
+			"end_date":    "2015-01-18",
+		})
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"info": {"domain": "example.com", "sub_domain": "www", "total": "60"},
+			"data": [
+				{"time": "2015-01-18 01:00:00", "num": "20"},
+				{"time": "2015-01-17 23:00:00", "num": 10},
+				{"time": "2015-01-18 00:00:00", "num": 30}
+			]}`)
+	})
+
+	start := time.Date(2015, 1, 17, 0, 0, 0, 0, analyticsLocation)
+	series, _, err := client.Domains.SubdomainAnalytics(AnalyticsQuery{
+		DomainID:  "1",
+		SubDomain: "www",
+		Period:    AnalyticsCustom,
+		Start:     start,
+		End:       start.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatalf("Domains.SubdomainAnalytics returned error: %v", err)
+	}
+	if series.Total != 60 || len(series.Points) != 3 {
+		t.Fatalf("Domains.SubdomainAnalytics returned %+v", series)
+	}
+	if series.Points[0].Count != 10 || series.Points[2].Count != 20 {
+		t.Errorf("Domains.SubdomainAnalytics points are not ordered by time: %+v", series.Points)
+	}
+
+	daily := series.Aggregate(BucketDaily)
+	if len(daily) != 2 || daily[0].Count != 10 || daily[1].Count != 50 {
+		t.Errorf("AnalyticsSeries.Aggregate(BucketDaily) = %+v", daily)
+	}
+	if !daily[1].Time.Equal(time.Date(2015, 1, 18, 0, 0, 0, 0, analyticsLocation)) {
+		t.Errorf("daily bucket starts at %v, want midnight", daily[1].Time)
+	}
+}
+
+func TestDomainsService_SubdomainAnalytics_RequiresSubdomain(t *testing.T) {
+	setup()
+	defer teardown()
+
+	if _, _, err := client.Domains.SubdomainAnalytics(AnalyticsQuery{DomainID: "1"}); err == nil {
+		t.Error("Domains.SubdomainAnalytics without subdomain returned no error")
+	}
+}
+
+func TestAggregateAnalytics_Hourly(t *testing.T) {
+	base := time.Date(2015, 1, 18, 10, 0, 0, 0, analyticsLocation)
+	points := []AnalyticsPoint{
+		{Time: base, Count: 1},
+		{Time: base.Add(20 * time.Minute), Count: 2},
+		{Time: base.Add(70 * time.Minute), Count: 4},
+	}
+
+	hourly := AggregateAnalytics(points, BucketHourly)
+	if len(hourly) != 2 || hourly[0].Count != 3 || hourly[1].Count != 4 {
+		t.Errorf("AggregateAnalytics(BucketHourly) = %+v", hourly)
+	}
+}
+
+func TestParseAnalyticsTime(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Time
+	}{
+		{"2024010112", time.Date(2024, 1, 1, 12, 0, 0, 0, analyticsLocation)},
+		{"20240101", time.Date(2024, 1, 1, 0, 0, 0, 0, analyticsLocation)},
+		{"2024-01-01 12", time.Date(2024, 1, 1, 12, 0, 0, 0, analyticsLocation)},
+		{"2024-01-01 12:30:05", time.Date(2024, 1, 1, 12, 30, 5, 0, analyticsLocation)},
+		{"1704081600", time.Unix(1704081600, 0).In(analyticsLocation)},
+	}
+	for _, tt := range tests {
+		got, err := parseAnalyticsTime(tt.s)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseAnalyticsTime(%q) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+	if _, err := parseAnalyticsTime("yesterday"); err == nil {
+		t.Errorf("parseAnalyticsTime of an invalid time returned no error")
+	}
+}
@@ -0,0 +1,159 @@
+package dnspod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RecordOperationKind is the kind of change applied by a RecordOperation.
+type RecordOperationKind string
+
+const (
+	RecordCreate RecordOperationKind = "create"
+	RecordUpdate RecordOperationKind = "update"
+	RecordDelete RecordOperationKind = "delete"
+	RecordStatus RecordOperationKind = "status"
+)
+
+// RecordOperation is a single record change applied by DomainsService.Bulk.
+type RecordOperation struct {
+	Kind     RecordOperationKind
+	DomainID string
+	RecordID string // required for update, delete and status
+	Record   Record // attributes for create and update
+	Status   string // "enable" or "disable", for status
+}
+
+// BulkOptions configures DomainsService.Bulk.
+type BulkOptions struct {
+	// Concurrency is the number of operations in flight, 1 if unset.
+	Concurrency int
+
+	// StopOnError skips the pending operations once one has failed,
+	// the operations already in flight being completed.
+	StopOnError bool
+}
+
+// BulkResult is the outcome of a RecordOperation.
+type BulkResult struct {
+	Operation RecordOperation
+	Record    Record // record returned by create and update
+	Err       error
+}
+
+// ErrSkipped is the error of the operations not performed because of StopOnError
+// or because the context was canceled.
+var ErrSkipped = errors.New("dnspod: operation skipped")
+
+// A BulkError is returned by DomainsService.Bulk when at least one operation failed.
+type BulkError struct {
+	Failed  int
+	Skipped int
+	First   error
+}
+
+// Error implements the error interface.
+func (e *BulkError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d operations failed", e.Failed)
+	if e.Skipped > 0 {
+		fmt.Fprintf(&b, ", %d skipped", e.Skipped)
+	}
+	if e.First != nil {
+		fmt.Fprintf(&b, ": %v", e.First)
+	}
+	return b.String()
+}
+
+// Unwrap returns the first error encountered.
+func (e *BulkError) Unwrap() error {
+	return e.First
+}
+
+// Bulk applies record operations with a bounded worker pool, in the order of ops.
+// The returned results are in the order of ops. Operations share the client,
+// including its middlewares, so a RateLimit middleware bounds the whole batch.
+// Their requests are bound to ctx: canceling it also stops those waiting or in flight.
+func (s *DomainsService) Bulk(ctx context.Context, ops []RecordOperation, opts BulkOptions) ([]BulkResult, error) {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// stop is set once an operation failed with StopOnError. Unlike canceling ctx, it leaves
+	// the requests in flight alone, as dnspod may apply them anyway.
+	var stop atomic.Bool
+	results := make([]BulkResult, len(ops))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = BulkResult{Operation: ops[i]}
+				if stop.Load() || ctx.Err() != nil {
+					results[i].Err = ErrSkipped
+					continue
+				}
+				results[i].Record, results[i].Err = s.applyRecordOperation(ctx, ops[i])
+				if results[i].Err != nil && opts.StopOnError {
+					stop.Store(true)
+				}
+			}
+		}()
+	}
+	for i := range ops {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, bulkResultsError(ctx, results)
+}
+
+// bulkResultsError returns the *BulkError of results, nil if every operation succeeded.
+func bulkResultsError(ctx context.Context, results []BulkResult) error {
+	bulkErr := &BulkError{}
+	for _, result := range results {
+		switch {
+		case result.Err == ErrSkipped:
+			bulkErr.Skipped++
+		case result.Err != nil:
+			bulkErr.Failed++
+			if bulkErr.First == nil {
+				bulkErr.First = result.Err
+			}
+		}
+	}
+	if bulkErr.Failed > 0 || bulkErr.Skipped > 0 {
+		if bulkErr.First == nil {
+			bulkErr.First = ctx.Err()
+		}
+		return bulkErr
+	}
+	return nil
+}
+
+// applyRecordOperation performs a single RecordOperation, its requests being bound to ctx.
+func (s *DomainsService) applyRecordOperation(ctx context.Context, op RecordOperation) (Record, error) {
+	switch op.Kind {
+	case RecordCreate:
+		record, _, err := s.createRecord(ctx, op.DomainID, op.Record)
+		return record, err
+	case RecordUpdate:
+		record, _, err := s.updateRecord(ctx, op.DomainID, op.RecordID, op.Record)
+		return record, err
+	case RecordDelete:
+		_, err := s.deleteRecord(ctx, op.DomainID, op.RecordID)
+		return Record{}, err
+	case RecordStatus:
+		_, err := s.updateRecordStatus(ctx, op.DomainID, op.RecordID, op.Status)
+		return Record{}, err
+	}
+	return Record{}, fmt.Errorf("unknown record operation %q", op.Kind)
+}
@@ -0,0 +1,172 @@
+package dnspod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDomainsService_Bulk(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var created int32
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&created, 1)
+		fmt.Fprintf(w, `{"status": {"code":"1","message":""},"record":{"id":"%d", "name":"%s"}}`, n, r.FormValue("sub_domain"))
+	})
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"8","message":"Record id invalid"}}`)
+	})
+
+	var ops []RecordOperation
+	for i := 0; i < 20; i++ {
+		ops = append(ops, RecordOperation{Kind: RecordCreate, DomainID: "1", Record: Record{Name: fmt.Sprintf("host%d", i), Type: "A", Value: "1.2.3.4"}})
+	}
+	ops = append(ops, RecordOperation{Kind: RecordDelete, DomainID: "1", RecordID: "42"})
+
+	results, err := client.Domains.Bulk(context.Background(), ops, BulkOptions{Concurrency: 4})
+
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || bulkErr.Failed != 1 || bulkErr.Skipped != 0 {
+		t.Fatalf("Domains.Bulk returned error %v, want a single failure", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("Domains.Bulk error does not unwrap to the *APIError: %v", err)
+	}
+	if created != 20 {
+		t.Errorf("Record.Create called %d times, want 20", created)
+	}
+	for i, result := range results[:20] {
+		testString(t, "Domains.Bulk record name", result.Record.Name, fmt.Sprintf("host%d", i))
+	}
+}
+
+func TestDomainsService_Bulk_order(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	mux.HandleFunc("/Record.Modify", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "modify "+r.FormValue("record_id"))
+		fmt.Fprintf(w, `{"status": {"code":"1"},"record":{"id":"%s"}}`, r.FormValue("record_id"))
+	})
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "remove "+r.FormValue("record_id"))
+		fmt.Fprint(w, `{"status": {"code":"8","message":"Record id invalid"}}`)
+	})
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "create "+r.FormValue("sub_domain"))
+		fmt.Fprint(w, `{"status": {"code":"1"},"record":{"id":"6"}}`)
+	})
+
+	// The operations run in the order given, an update before the delete of its record.
+	ops := []RecordOperation{
+		{Kind: RecordCreate, DomainID: "1", Record: Record{Name: "new", Type: "A", Value: "1.2.3.4"}},
+		{Kind: RecordUpdate, DomainID: "1", RecordID: "5", Record: Record{Name: "www", Type: "A", Value: "1.2.3.4"}},
+		{Kind: RecordDelete, DomainID: "1", RecordID: "5"},
+		{Kind: RecordCreate, DomainID: "1", Record: Record{Name: "skipped", Type: "A", Value: "1.2.3.4"}},
+	}
+	results, err := client.Domains.Bulk(context.Background(), ops, BulkOptions{StopOnError: true})
+
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || bulkErr.Failed != 1 || bulkErr.Skipped != 1 {
+		t.Fatalf("Domains.Bulk returned error %v, want 1 failure and 1 skipped", err)
+	}
+	if results[0].Err != nil || results[1].Err != nil {
+		t.Errorf("Domains.Bulk failed the operations before the delete: %+v", results[:2])
+	}
+	testString(t, "Domains.Bulk calls", fmt.Sprint(calls), "[create new modify 5 remove 5]")
+}
+
+func TestDomainsService_Bulk_StopOnError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.Status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"8","message":"Record id invalid"}}`)
+	})
+
+	var ops []RecordOperation
+	for i := 0; i < 10; i++ {
+		ops = append(ops, RecordOperation{Kind: RecordStatus, DomainID: "1", RecordID: fmt.Sprint(i), Status: "disable"})
+	}
+
+	results, err := client.Domains.Bulk(context.Background(), ops, BulkOptions{StopOnError: true})
+
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || bulkErr.Failed != 1 || bulkErr.Skipped != 9 {
+		t.Fatalf("Domains.Bulk returned error %v, want 1 failure and 9 skipped", err)
+	}
+	if results[9].Err != ErrSkipped {
+		t.Errorf("last operation error = %v, want ErrSkipped", results[9].Err)
+	}
+}
+
+func TestDomainsService_Bulk_StopOnError_inFlight(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.Status", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("record_id") == "1" {
+			fmt.Fprint(w, `{"status": {"code":"8","message":"Record id invalid"}}`)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, `{"status": {"code":"1","message":""}}`)
+	})
+
+	var ops []RecordOperation
+	for i := 0; i < 5; i++ {
+		ops = append(ops, RecordOperation{Kind: RecordStatus, DomainID: "1", RecordID: fmt.Sprint(i), Status: "disable"})
+	}
+
+	results, err := client.Domains.Bulk(context.Background(), ops, BulkOptions{Concurrency: 2, StopOnError: true})
+
+	var bulkErr *BulkError
+	var apiErr *APIError
+	if !errors.As(err, &bulkErr) || bulkErr.Failed != 1 || bulkErr.Skipped != 3 || !errors.As(bulkErr.First, &apiErr) {
+		t.Fatalf("Domains.Bulk returned error %v, want 1 failure and 3 skipped", err)
+	}
+	// The operation in flight when the other failed completes.
+	if results[0].Err != nil {
+		t.Errorf("first operation error = %v, want nil", results[0].Err)
+	}
+}
+
+func TestDomainsService_Bulk_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":""}}`)
+	})
+	client.Use(RateLimit(NewTokenBucket(0.001, 1)))
+
+	ops := []RecordOperation{
+		{Kind: RecordDelete, DomainID: "1", RecordID: "1"},
+		{Kind: RecordDelete, DomainID: "1", RecordID: "2"},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	done := make(chan struct{})
+	var results []BulkResult
+	go func() {
+		defer close(done)
+		results, _ = client.Domains.Bulk(ctx, ops, BulkOptions{})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Domains.Bulk still waiting on the rate limit after its context was canceled")
+	}
+	if results[1].Err != context.Canceled {
+		t.Errorf("second operation error = %v, want context.Canceled", results[1].Err)
+	}
+}
@@ -0,0 +1,235 @@
+package dnspod
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheBackend stores cached response bodies.
+// Implementations must be safe for concurrent use.
+type CacheBackend interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// Cache is a middleware caching the responses of read-only actions
+// (List, Info, Line and Detail), e.g.
+//
+//	client.Use(dnspod.NewCache(dnspod.NewLRUCache(1024), time.Minute).Middleware)
+//
+// Cached responses of a domain are invalidated as soon as a mutating action on the same
+// domain succeeds, along with the responses of the same service not scoped to a domain
+// (e.g. Monitor.List on Monitor.Create). All of them are invalidated when a Domain.* action
+// or a mutating action not scoped to a domain (e.g. Monitor.Setstatus) succeeds. Domains are
+// identified by the domain_id or domain parameter as sent, so a domain should consistently be
+// referred to either by ID or by name.
+type Cache struct {
+	Backend CacheBackend
+	TTL     time.Duration
+
+	mu          sync.Mutex
+	global      uint64
+	generations map[string]uint64
+}
+
+// NewCache returns a Cache storing responses in backend for ttl.
+func NewCache(backend CacheBackend, ttl time.Duration) *Cache {
+	return &Cache{Backend: backend, TTL: ttl, generations: map[string]uint64{}}
+}
+
+// cacheHitHeader is set on the responses served from the cache.
+const cacheHitHeader = "X-Dnspod-Cache"
+
+type noCacheKey struct{}
+
+// NoCache returns a copy of ctx whose requests bypass the Cache: their responses are
+// neither served from it nor stored, e.g. for health checks that must reach dnspod.
+func NoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// bypassCache reports whether the request was bound to a NoCache context.
+func bypassCache(req *Request) bool {
+	return req.Context != nil && req.Context.Value(noCacheKey{}) != nil
+}
+
+// cacheable reports whether the result of action may be cached.
+func cacheable(action string) bool {
+	i := strings.LastIndex(action, ".")
+	switch action[i+1:] {
+	case "List", "Info", "Line", "Detail":
+		return true
+	}
+	return false
+}
+
+// readOnlyActions are the actions leaving the account unchanged that are not cacheable,
+// declared by the services with registerReadOnly.
+var readOnlyActions = map[string]bool{}
+
+// registerReadOnly declares actions leaving the account unchanged, so that the Cache
+// does not invalidate its responses when they succeed.
+func registerReadOnly(actions ...string) {
+	for _, action := range actions {
+		readOnlyActions[action] = true
+	}
+}
+
+// readOnly reports whether action leaves the account unchanged, cached or not.
+func readOnly(action string) bool {
+	return cacheable(action) || readOnlyActions[action]
+}
+
+// actionService returns the service of an action, e.g. "Monitor." for Monitor.Create,
+// under which the Cache keeps the generation of the responses not scoped to a domain.
+func actionService(action string) string {
+	return action[:strings.LastIndex(action, ".")+1]
+}
+
+// payloadDomain returns the domain a payload refers to, if any.
+func payloadDomain(req *Request) string {
+	if id := req.Payload.Get("domain_id"); id != "" {
+		return id
+	}
+	return req.Payload.Get("domain")
+}
+
+// Middleware serves read-only actions from the cache and invalidates it on mutating ones.
+func (c *Cache) Middleware(next Handler) Handler {
+	return HandlerFunc(func(req *Request) (*Result, error) {
+		domain := payloadDomain(req)
+		if !cacheable(req.Action) {
+			result, err := next.Handle(req)
+			if err == nil && !readOnly(req.Action) {
+				if strings.HasPrefix(req.Action, "Domain.") || domain == "" {
+					c.InvalidateAll()
+				} else {
+					c.Invalidate(domain)
+					c.Invalidate(actionService(req.Action))
+				}
+			}
+			return result, err
+		}
+		if bypassCache(req) {
+			return next.Handle(req)
+		}
+
+		key := c.key(req, domain)
+		if body, ok := c.Backend.Get(key); ok {
+			status, _ := decodeStatus(body)
+			res := &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: http.Header{}}
+			res.Header.Set(cacheHitHeader, "hit")
+			return &Result{Response: &Response{Response: res}, Status: status, Body: body}, nil
+		}
+
+		result, err := next.Handle(req)
+		if err == nil && result != nil {
+			c.Backend.Set(key, result.Body, c.TTL)
+		}
+		return result, err
+	})
+}
+
+// key derives the cache key from the action, the normalized payload
+// and the current generations, so that credentials never reach the backend.
+func (c *Cache) key(req *Request, domain string) string {
+	scope := domain
+	if scope == "" {
+		scope = actionService(req.Action)
+	}
+	c.mu.Lock()
+	generation := strconv.FormatUint(c.global, 10) + "." + strconv.FormatUint(c.generations[scope], 10)
+	c.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(req.Action + "?" + req.Payload.Encode()))
+	return generation + ":" + req.Action + ":" + hex.EncodeToString(sum[:])
+}
+
+// Invalidate drops the cached responses of a domain, by ID or name.
+func (c *Cache) Invalidate(domain string) {
+	c.mu.Lock()
+	c.generations[domain]++
+	c.mu.Unlock()
+}
+
+// InvalidateAll drops every cached response.
+func (c *Cache) InvalidateAll() {
+	c.mu.Lock()
+	c.global++
+	c.generations = map[string]uint64{}
+	c.mu.Unlock()
+}
+
+// LRUCache is an in-memory CacheBackend evicting the least recently used entries.
+type LRUCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache returns an LRUCache holding at most size entries.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{size: size, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// Get implements the CacheBackend interface.
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		l.order.Remove(e)
+		delete(l.entries, key)
+		return nil, false
+	}
+	l.order.MoveToFront(e)
+	return entry.value, true
+}
+
+// Set implements the CacheBackend interface.
+func (l *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok := l.entries[key]; ok {
+		entry := e.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = time.Now().Add(ttl)
+		l.order.MoveToFront(e)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: time.Now().Add(ttl)})
+	for l.size > 0 && l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of entries held by the cache.
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
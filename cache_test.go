@@ -0,0 +1,114 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCache_Middleware(t *testing.T) {
+	setup()
+	defer teardown()
+
+	infoCalls := 0
+	mux.HandleFunc("/Domain.Info", func(w http.ResponseWriter, r *http.Request) {
+		infoCalls++
+		fmt.Fprint(w, `{"status": {"code":"1","message":""},"domain": {"id":1, "name":"example.com"}}`)
+	})
+	listCalls := 0
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		listCalls++
+		fmt.Fprint(w, `{"status": {"code":"1","message":""},"records":[{"id":"44146112", "name":"www"}]}`)
+	})
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":""}}`)
+	})
+
+	client.Use(NewCache(NewLRUCache(16), time.Minute).Middleware)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Domains.Get(1); err != nil {
+			t.Fatalf("Domains.Get returned error: %v", err)
+		}
+		if _, _, err := client.Domains.ListRecords(RecordQuery{DomainID: "1"}); err != nil {
+			t.Fatalf("Domains.ListRecords returned error: %v", err)
+		}
+	}
+	if infoCalls != 1 || listCalls != 1 {
+		t.Errorf("server called %d/%d times, want 1/1", infoCalls, listCalls)
+	}
+
+	records, res, _ := client.Domains.ListRecords(RecordQuery{DomainID: "1"})
+	testString(t, "cache header", res.Header.Get(cacheHitHeader), "hit")
+	testString(t, "cached record", records.List[0].Name, "www")
+
+	if _, err := client.Domains.DeleteRecord("1", "44146112"); err != nil {
+		t.Fatalf("Domains.DeleteRecord returned error: %v", err)
+	}
+	client.Domains.ListRecords(RecordQuery{DomainID: "1"})
+	client.Domains.ListRecords(RecordQuery{DomainID: "2"})
+	if listCalls != 3 {
+		t.Errorf("Record.List called %d times after invalidation, want 3", listCalls)
+	}
+}
+
+func TestCache_Middleware_monitors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	listCalls := 0
+	mux.HandleFunc("/Monitor.List", func(w http.ResponseWriter, r *http.Request) {
+		listCalls++
+		fmt.Fprint(w, `{"status": {"code":"1","message":""},"monitors":[]}`)
+	})
+	mux.HandleFunc("/Monitor.Listsubdomain", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":""},"subdomain":["www"]}`)
+	})
+	mux.HandleFunc("/Monitor.Create", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":""},"monitor":{"monitor_id":"1"}}`)
+	})
+
+	client.Use(NewCache(NewLRUCache(16), time.Minute).Middleware)
+
+	client.Monitors.List()
+	if _, _, err := client.Monitors.ListSubdomains("1"); err != nil {
+		t.Fatalf("Monitors.ListSubdomains returned error: %v", err)
+	}
+	client.Monitors.List()
+	if listCalls != 1 {
+		t.Errorf("Monitor.List called %d times before any write, want 1", listCalls)
+	}
+
+	if _, _, err := client.Monitors.Create(Monitor{DomainID: "1", RecordID: "2"}); err != nil {
+		t.Fatalf("Monitors.Create returned error: %v", err)
+	}
+	client.Monitors.List()
+	if listCalls != 2 {
+		t.Errorf("Monitor.List called %d times after Monitor.Create, want 2", listCalls)
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := NewLRUCache(2)
+
+	cache.Set("a", []byte("a"), time.Minute)
+	cache.Set("b", []byte("b"), time.Minute)
+	cache.Get("a")
+	cache.Set("c", []byte("c"), time.Minute)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("least recently used entry was not evicted")
+	}
+	if v, ok := cache.Get("a"); !ok || string(v) != "a" {
+		t.Errorf("Get(a) = %q, %v", v, ok)
+	}
+
+	cache.Set("d", []byte("d"), -time.Second)
+	if _, ok := cache.Get("d"); ok {
+		t.Errorf("expired entry was returned")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", cache.Len())
+	}
+}
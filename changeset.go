@@ -0,0 +1,334 @@
+package dnspod
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrChangeSetClosed is returned by the methods of a ChangeSet already committed or rolled back.
+var ErrChangeSetClosed = errors.New("dnspod: change set closed")
+
+// ChangeSet applies record changes as a transaction: it records the operation undoing
+// every successful change and, when a change fails or on Rollback, replays them in reverse, e.g.
+//
+//	cs, err := client.Domains.NewChangeSet("/var/lib/app/changes.journal")
+//	...
+//	if _, err := cs.DeleteRecord("1", cnameID); err != nil {
+//		return err // nothing to undo
+//	}
+//	if _, err := cs.CreateRecord("1", a); err != nil {
+//		return err // the CNAME record was recreated
+//	}
+//	return cs.Commit()
+//
+// When a journal path is given, the undo operations are appended to it as they are recorded,
+// so that RecoverChangeSet can roll back the changes of a crashed process.
+// A ChangeSet is safe for concurrent use.
+type ChangeSet struct {
+	domains *DomainsService
+	journal string
+
+	mu     sync.Mutex
+	undo   []RecordOperation
+	closed bool
+}
+
+// A ChangeSetError is returned when a change failed and the change set was rolled back.
+type ChangeSetError struct {
+	Op          RecordOperation // failed change
+	Err         error
+	RollbackErr error // error rolling back the previous changes, if any
+}
+
+// Error implements the error interface.
+func (e *ChangeSetError) Error() string {
+	msg := fmt.Sprintf("%s %s failed: %v", e.Op.Kind, e.Op.RecordID, e.Err)
+	if e.RollbackErr != nil {
+		msg += fmt.Sprintf(" (rollback failed: %v)", e.RollbackErr)
+	}
+	return msg
+}
+
+// Unwrap returns the error of the failed change.
+func (e *ChangeSetError) Unwrap() error {
+	return e.Err
+}
+
+// journalEntry is a line of a change set journal: either an undo operation
+// recorded, or the last pending one replayed, along with the new ID of the
+// record it recreated.
+type journalEntry struct {
+	Undo   *RecordOperation  `json:"undo,omitempty"`
+	Undone bool              `json:"undone,omitempty"`
+	Remap  map[string]string `json:"remap,omitempty"`
+}
+
+// NewChangeSet returns an empty change set, journaled to the given path unless empty.
+// It fails if the journal holds changes still to be recovered.
+func (s *DomainsService) NewChangeSet(journal string) (*ChangeSet, error) {
+	if journal != "" {
+		pending, err := readJournal(journal)
+		if err != nil {
+			return nil, err
+		}
+		if len(pending) > 0 {
+			return nil, fmt.Errorf("dnspod: journal %s holds %d changes to recover", journal, len(pending))
+		}
+	}
+	return &ChangeSet{domains: s, journal: journal}, nil
+}
+
+// RecoverChangeSet returns the change set journaled to path by a previous process,
+// to be rolled back or committed.
+func (s *DomainsService) RecoverChangeSet(journal string) (*ChangeSet, error) {
+	pending, err := readJournal(journal)
+	if err != nil {
+		return nil, err
+	}
+	return &ChangeSet{domains: s, journal: journal, undo: pending}, nil
+}
+
+// readJournal returns the undo operations pending in a journal, a missing journal being empty.
+func readJournal(path string) ([]RecordOperation, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pending []RecordOperation
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		entry := journalEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// The last line may have been cut short by a crash.
+			break
+		}
+		switch {
+		case entry.Undo != nil:
+			pending = append(pending, *entry.Undo)
+		case entry.Undone && len(pending) > 0:
+			pending = pending[:len(pending)-1]
+			for oldID, newID := range entry.Remap {
+				remap(pending, oldID, newID)
+			}
+		}
+	}
+	return pending, scanner.Err()
+}
+
+// record appends an entry to the journal.
+func (c *ChangeSet) record(entry journalEntry) error {
+	if c.journal == "" {
+		return nil
+	}
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(bs, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// apply performs op, its requests being bound to ctx, and records inverse, built from
+// the result of op, as its undo. On failure, the change set is rolled back, without the
+// cancellation of ctx so that a canceled change does not leave the previous ones in place.
+func (c *ChangeSet) apply(ctx context.Context, op RecordOperation, inverse func(Record) RecordOperation) (Record, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return Record{}, ErrChangeSetClosed
+	}
+
+	record, err := c.domains.applyRecordOperation(ctx, op)
+	// A create or update returning its record with an error, e.g. setting its remark,
+	// did change the record, so it is undone along with the others.
+	if err == nil || record.ID != "" {
+		undo := inverse(record)
+		c.undo = append(c.undo, undo)
+		if jerr := c.record(journalEntry{Undo: &undo}); jerr != nil && err == nil {
+			err = fmt.Errorf("journal: %w", jerr)
+		}
+	}
+	if err != nil {
+		return Record{}, &ChangeSetError{Op: op, Err: err, RollbackErr: c.rollback(context.WithoutCancel(ctx))}
+	}
+	return record, nil
+}
+
+// abort rolls back the change set when the attributes of a change could not be fetched.
+func (c *ChangeSet) abort(op RecordOperation, err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrChangeSetClosed
+	}
+	return &ChangeSetError{Op: op, Err: err, RollbackErr: c.rollback(context.Background())}
+}
+
+// before fetches the attributes recreating a record.
+func (c *ChangeSet) before(domainID, recordID string) (Record, error) {
+	record, _, err := c.domains.GetRecord(domainID, recordID)
+	if err != nil {
+		return Record{}, err
+	}
+	return restorable(record), nil
+}
+
+// CreateRecord creates a record, to be deleted on rollback.
+func (c *ChangeSet) CreateRecord(domainID string, record Record) (Record, error) {
+	return c.create(context.Background(), domainID, record)
+}
+
+func (c *ChangeSet) create(ctx context.Context, domainID string, record Record) (Record, error) {
+	op := RecordOperation{Kind: RecordCreate, DomainID: domainID, Record: record}
+	return c.apply(ctx, op, func(created Record) RecordOperation {
+		return RecordOperation{Kind: RecordDelete, DomainID: domainID, RecordID: created.ID}
+	})
+}
+
+// UpdateRecord updates a record, to be restored on rollback.
+func (c *ChangeSet) UpdateRecord(domainID, recordID string, record Record) (Record, error) {
+	before, err := c.before(domainID, recordID)
+	if err != nil {
+		return Record{}, c.abort(RecordOperation{Kind: RecordUpdate, DomainID: domainID, RecordID: recordID, Record: record}, err)
+	}
+	return c.update(context.Background(), domainID, recordID, before, record)
+}
+
+func (c *ChangeSet) update(ctx context.Context, domainID, recordID string, before, after Record) (Record, error) {
+	op := RecordOperation{Kind: RecordUpdate, DomainID: domainID, RecordID: recordID, Record: after}
+	return c.apply(ctx, op, func(Record) RecordOperation {
+		return RecordOperation{Kind: RecordUpdate, DomainID: domainID, RecordID: recordID, Record: before}
+	})
+}
+
+// DeleteRecord deletes a record, to be recreated on rollback, with a new ID.
+func (c *ChangeSet) DeleteRecord(domainID, recordID string) (Record, error) {
+	before, err := c.before(domainID, recordID)
+	if err != nil {
+		return Record{}, c.abort(RecordOperation{Kind: RecordDelete, DomainID: domainID, RecordID: recordID}, err)
+	}
+	return before, c.delete(context.Background(), domainID, recordID, before)
+}
+
+func (c *ChangeSet) delete(ctx context.Context, domainID, recordID string, before Record) error {
+	op := RecordOperation{Kind: RecordDelete, DomainID: domainID, RecordID: recordID}
+	_, err := c.apply(ctx, op, func(Record) RecordOperation {
+		// RecordID keeps the former ID, for the undo operations referring to it.
+		return RecordOperation{Kind: RecordCreate, DomainID: domainID, RecordID: recordID, Record: before}
+	})
+	return err
+}
+
+// UpdateRecordStatus enables or disables a record, to be restored on rollback.
+func (c *ChangeSet) UpdateRecordStatus(domainID, recordID, status string) error {
+	before, err := c.before(domainID, recordID)
+	if err != nil {
+		return c.abort(RecordOperation{Kind: RecordStatus, DomainID: domainID, RecordID: recordID, Status: status}, err)
+	}
+	return c.updateStatus(context.Background(), domainID, recordID, before.Status, status)
+}
+
+func (c *ChangeSet) updateStatus(ctx context.Context, domainID, recordID, before, status string) error {
+	op := RecordOperation{Kind: RecordStatus, DomainID: domainID, RecordID: recordID, Status: status}
+	_, err := c.apply(ctx, op, func(Record) RecordOperation {
+		return RecordOperation{Kind: RecordStatus, DomainID: domainID, RecordID: recordID, Status: before}
+	})
+	return err
+}
+
+// Len returns the number of changes to undo on rollback.
+func (c *ChangeSet) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.undo)
+}
+
+// Commit keeps the changes and removes the journal.
+func (c *ChangeSet) Commit() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrChangeSetClosed
+	}
+	c.closed = true
+	c.undo = nil
+	return c.removeJournal()
+}
+
+// Rollback undoes the changes in reverse order. When an undo operation fails, Rollback stops
+// and may be called again to retry, the remaining changes being kept in the journal.
+func (c *ChangeSet) Rollback() error {
+	return c.rollbackContext(context.Background())
+}
+
+func (c *ChangeSet) rollbackContext(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrChangeSetClosed
+	}
+	return c.rollback(ctx)
+}
+
+func (c *ChangeSet) rollback(ctx context.Context) error {
+	for len(c.undo) > 0 {
+		op := c.undo[len(c.undo)-1]
+		record, err := c.domains.applyRecordOperation(ctx, op)
+		if err != nil {
+			return err
+		}
+		c.undo = c.undo[:len(c.undo)-1]
+
+		// Records recreated get a new ID, which the earlier undo operations must use.
+		entry := journalEntry{Undone: true}
+		if op.Kind == RecordCreate && op.RecordID != "" {
+			entry.Remap = map[string]string{op.RecordID: record.ID}
+			remap(c.undo, op.RecordID, record.ID)
+		}
+		if err := c.record(entry); err != nil {
+			return fmt.Errorf("journal: %w", err)
+		}
+	}
+	c.closed = true
+	return c.removeJournal()
+}
+
+// remap makes undo operations refer to a recreated record by its new ID.
+func remap(ops []RecordOperation, oldID, newID string) {
+	for i := range ops {
+		if ops[i].RecordID == oldID {
+			ops[i].RecordID = newID
+		}
+	}
+}
+
+func (c *ChangeSet) removeJournal() error {
+	if c.journal == "" {
+		return nil
+	}
+	if err := os.Remove(c.journal); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
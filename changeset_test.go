@@ -0,0 +1,219 @@
+package dnspod
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// changeSetHandlers records the record calls, Record.Create returning IDs from 10
+// and Record.Modify and Record.Status failing for the record ID *fail.
+func changeSetHandlers(calls *[]string, fail *string) {
+	id := 10
+	mux.HandleFunc("/Record.Info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"status": {"code":"1"},"record":{"id":"%s","name":"old","type":"A","value":"3.3.3.3","line":"默认","ttl":"600","enabled":"1"}}`, r.FormValue("record_id"))
+	})
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, "create "+r.FormValue("sub_domain"))
+		fmt.Fprintf(w, `{"status": {"code":"1"},"record":{"id":"%d","name":"%s"}}`, id, r.FormValue("sub_domain"))
+		id++
+	})
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, "remove "+r.FormValue("record_id"))
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+	mux.HandleFunc("/Record.Modify", func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, "modify "+r.FormValue("record_id")+" ttl "+r.FormValue("ttl"))
+		if r.FormValue("record_id") == *fail {
+			fmt.Fprint(w, `{"status": {"code":"8","message":"Record id invalid"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"status": {"code":"1"},"record":{"id":"%s"}}`, r.FormValue("record_id"))
+	})
+	mux.HandleFunc("/Record.Status", func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, "status "+r.FormValue("record_id")+" "+r.FormValue("status"))
+		if r.FormValue("record_id") == *fail {
+			fmt.Fprint(w, `{"status": {"code":"8","message":"Record id invalid"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+}
+
+func TestChangeSet_Rollback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	fail := ""
+	changeSetHandlers(&calls, &fail)
+
+	cs, _ := client.Domains.NewChangeSet("")
+	if _, err := cs.CreateRecord("1", Record{Name: "new", Type: "A", Value: "4.4.4.4", Line: DefaultLine}); err != nil {
+		t.Fatalf("ChangeSet.CreateRecord returned error: %v", err)
+	}
+	if _, err := cs.UpdateRecord("1", "2", Record{Name: "www", Type: "A", Value: "1.1.1.1", Line: DefaultLine, TTL: "300"}); err != nil {
+		t.Fatalf("ChangeSet.UpdateRecord returned error: %v", err)
+	}
+	if err := cs.UpdateRecordStatus("1", "3", "disable"); err != nil {
+		t.Fatalf("ChangeSet.UpdateRecordStatus returned error: %v", err)
+	}
+	before, err := cs.DeleteRecord("1", "3")
+	if err != nil {
+		t.Fatalf("ChangeSet.DeleteRecord returned error: %v", err)
+	}
+	if before.Value != "3.3.3.3" || before.Status != "enable" || before.ID != "" {
+		t.Errorf("ChangeSet.DeleteRecord returned %+v", before)
+	}
+	if cs.Len() != 4 {
+		t.Errorf("ChangeSet.Len returned %d, want 4", cs.Len())
+	}
+
+	if err := cs.Rollback(); err != nil {
+		t.Fatalf("ChangeSet.Rollback returned error: %v", err)
+	}
+	// Record 3 is recreated as record 11, whose status is then restored.
+	want := "create new, modify 2 ttl 300, status 3 disable, remove 3, create old, status 11 enable, modify 2 ttl 600, remove 10"
+	if got := strings.Join(calls, ", "); got != want {
+		t.Errorf("ChangeSet calls = %s, want %s", got, want)
+	}
+
+	if _, err := cs.CreateRecord("1", Record{Name: "new"}); err != ErrChangeSetClosed {
+		t.Errorf("ChangeSet.CreateRecord after Rollback returned %v, want ErrChangeSetClosed", err)
+	}
+	if err := cs.Commit(); err != ErrChangeSetClosed {
+		t.Errorf("ChangeSet.Commit after Rollback returned %v, want ErrChangeSetClosed", err)
+	}
+}
+
+func TestChangeSet_failure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	fail := "2"
+	changeSetHandlers(&calls, &fail)
+
+	cs, _ := client.Domains.NewChangeSet("")
+	cs.CreateRecord("1", Record{Name: "new", Type: "A", Value: "4.4.4.4", Line: DefaultLine})
+	_, err := cs.UpdateRecord("1", "2", Record{Name: "www", Type: "A", Value: "1.1.1.1", Line: DefaultLine, TTL: "300"})
+
+	var csErr *ChangeSetError
+	if !errors.As(err, &csErr) || csErr.Op.Kind != RecordUpdate || csErr.RollbackErr != nil {
+		t.Fatalf("ChangeSet.UpdateRecord returned %v, want a rolled back update", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("ChangeSet.UpdateRecord returned %v, want an *APIError", err)
+	}
+	want := "create new, modify 2 ttl 300, remove 10"
+	if got := strings.Join(calls, ", "); got != want {
+		t.Errorf("ChangeSet calls = %s, want %s", got, want)
+	}
+	if cs.Len() != 0 {
+		t.Errorf("ChangeSet.Len returned %d, want 0", cs.Len())
+	}
+}
+
+func TestChangeSet_remarkFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	fail := ""
+	changeSetHandlers(&calls, &fail)
+	mux.HandleFunc("/Record.Remark", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "remark "+r.FormValue("record_id"))
+		fmt.Fprint(w, `{"status": {"code":"-1","message":"Login failed"}}`)
+	})
+
+	cs, _ := client.Domains.NewChangeSet("")
+	_, err := cs.CreateRecord("1", Record{Name: "new", Type: "A", Value: "4.4.4.4", Line: DefaultLine, Remark: "web"})
+
+	var csErr *ChangeSetError
+	if !errors.As(err, &csErr) || csErr.Op.Kind != RecordCreate || csErr.RollbackErr != nil {
+		t.Fatalf("ChangeSet.CreateRecord returned %v, want a rolled back create", err)
+	}
+	want := "create new, remark 10, remove 10"
+	if got := strings.Join(calls, ", "); got != want {
+		t.Errorf("ChangeSet calls = %s, want %s", got, want)
+	}
+	if cs.Len() != 0 {
+		t.Errorf("ChangeSet.Len returned %d, want 0", cs.Len())
+	}
+}
+
+func TestChangeSet_journal(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	fail := ""
+	changeSetHandlers(&calls, &fail)
+
+	journal := filepath.Join(t.TempDir(), "changes.journal")
+	cs, err := client.Domains.NewChangeSet(journal)
+	if err != nil {
+		t.Fatalf("NewChangeSet returned error: %v", err)
+	}
+	cs.UpdateRecordStatus("1", "3", "disable")
+	cs.DeleteRecord("1", "3")
+	cs.CreateRecord("1", Record{Name: "new", Type: "A", Value: "4.4.4.4", Line: DefaultLine})
+
+	// Rolling back fails after the deleted record is recreated, then the process crashes
+	// while journaling a change.
+	fail = "11"
+	if err := cs.Rollback(); err == nil {
+		t.Fatalf("ChangeSet.Rollback returned no error")
+	}
+	f, _ := os.OpenFile(journal, os.O_APPEND|os.O_WRONLY, 0)
+	fmt.Fprint(f, `{"undo":{"Kind":"del`)
+	f.Close()
+	fail, calls = "", nil
+
+	if _, err := client.Domains.NewChangeSet(journal); err == nil {
+		t.Errorf("NewChangeSet returned no error for a journal holding changes")
+	}
+	recovered, err := client.Domains.RecoverChangeSet(journal)
+	if err != nil {
+		t.Fatalf("RecoverChangeSet returned error: %v", err)
+	}
+	if recovered.Len() != 1 {
+		t.Fatalf("RecoverChangeSet returned %d changes, want 1", recovered.Len())
+	}
+	if err := recovered.Rollback(); err != nil {
+		t.Fatalf("ChangeSet.Rollback returned error: %v", err)
+	}
+	if got, want := strings.Join(calls, ", "), "status 11 enable"; got != want {
+		t.Errorf("ChangeSet calls = %s, want %s", got, want)
+	}
+	if _, err := os.Stat(journal); !os.IsNotExist(err) {
+		t.Errorf("journal not removed by Rollback: %v", err)
+	}
+}
+
+func TestChangeSet_Commit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	fail := ""
+	changeSetHandlers(&calls, &fail)
+
+	journal := filepath.Join(t.TempDir(), "changes.journal")
+	cs, _ := client.Domains.NewChangeSet(journal)
+	cs.CreateRecord("1", Record{Name: "new", Type: "A", Value: "4.4.4.4", Line: DefaultLine})
+	if err := cs.Commit(); err != nil {
+		t.Fatalf("ChangeSet.Commit returned error: %v", err)
+	}
+	if _, err := os.Stat(journal); !os.IsNotExist(err) {
+		t.Errorf("journal not removed by Commit: %v", err)
+	}
+	if err := cs.Rollback(); err != ErrChangeSetClosed {
+		t.Errorf("ChangeSet.Rollback after Commit returned %v, want ErrChangeSetClosed", err)
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/decker502/dnspod-go"
+	"github.com/decker502/dnspod-go/zoneconfig"
+	"gopkg.in/yaml.v3"
+)
+
+type command struct {
+	client *dnspod.Client
+	out    printer
+	stderr io.Writer
+}
+
+// flags returns a flag set for a subcommand, reporting errors on stderr.
+func (c *command) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("dnspodctl "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parse parses the subcommand flags and checks the required ones are set,
+// a zero integer flag, e.g. -id 0, counting as missing.
+func (c *command) parse(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	for _, name := range required {
+		f := fs.Lookup(name)
+		if v := f.Value.String(); v == "" || (v == "0" && f.DefValue == "0") {
+			return usageError(c.stderr, fmt.Errorf("%s: -%s is required", fs.Name(), name))
+		}
+	}
+	return nil
+}
+
+func (c *command) domains(args []string) error {
+	if len(args) == 0 {
+		return usageError(c.stderr, fmt.Errorf("usage: dnspodctl domains list|get|create|delete|status"))
+	}
+	fs := c.flags("domains " + args[0])
+	subcommand := args[0]
+	args = args[1:]
+
+	switch subcommand {
+	case "list":
+		query := dnspod.DomainQuery{}
+		fs.StringVar(&query.Type, "type", "", "domain type: all, mine, share, ismark, pause, vip, recent, share_out")
+		fs.StringVar(&query.Keyword, "keyword", "", "search keyword")
+		fs.StringVar(&query.GroupId, "group-id", "", "domain group ID")
+		if err := c.parse(fs, args); err != nil {
+			return err
+		}
+		domains, _, err := c.client.Domains.List(query)
+		if err != nil {
+			return err
+		}
+		return c.out.domains(domains.List)
+
+	case "get":
+		id := fs.Int("id", 0, "domain ID")
+		if err := c.parse(fs, args, "id"); err != nil {
+			return err
+		}
+		domain, _, err := c.client.Domains.Get(*id)
+		if err != nil {
+			return err
+		}
+		return c.out.domains([]dnspod.Domain{domain})
+
+	case "create":
+		domain := dnspod.Domain{}
+		fs.StringVar(&domain.Name, "name", "", "domain name")
+		fs.StringVar(&domain.GroupID, "group-id", "", "domain group ID")
+		fs.StringVar(&domain.IsMark, "mark", "", "star the domain: yes or no")
+		if err := c.parse(fs, args, "name"); err != nil {
+			return err
+		}
+		domain, _, err := c.client.Domains.Create(domain)
+		if err != nil {
+			return err
+		}
+		return c.out.domains([]dnspod.Domain{domain})
+
+	case "delete":
+		id := fs.Int("id", 0, "domain ID")
+		if err := c.parse(fs, args, "id"); err != nil {
+			return err
+		}
+		_, err := c.client.Domains.Delete(*id)
+		return err
+
+	case "status":
+		id := fs.String("id", "", "domain ID")
+		status := fs.String("status", "", "enable or disable")
+		if err := c.parse(fs, args, "id", "status"); err != nil {
+			return err
+		}
+		_, err := c.client.Domains.UpdateStatus(*id, *status)
+		return err
+	}
+	return usageError(c.stderr, fmt.Errorf("unknown subcommand %q", subcommand))
+}
+
+// recordFlags registers the flags describing record attributes.
+func recordFlags(fs *flag.FlagSet, record *dnspod.Record) {
+	fs.StringVar(&record.Name, "name", "", "sub domain, @ for the apex")
+	fs.StringVar(&record.Type, "type", "", "record type: A, AAAA, CNAME, MX, TXT, ...")
+	fs.StringVar(&record.Value, "value", "", "record value")
+	fs.StringVar(&record.Line, "line", "", "record line")
+	fs.StringVar(&record.LineID, "line-id", "", "record line ID")
+	fs.StringVar(&record.TTL, "ttl", "", "record TTL")
+	fs.StringVar(&record.MX, "mx", "", "MX priority")
+	fs.StringVar(&record.Status, "status", "", "enable or disable")
+	fs.StringVar(&record.Weight, "weight", "", "weight between 0 and 100")
+	fs.StringVar(&record.URLRedirectCode, "redirect-code", "", "301 or 302, for 显性URL records")
+}
+
+func (c *command) records(args []string) error {
+	if len(args) == 0 {
+		return usageError(c.stderr, fmt.Errorf("usage: dnspodctl records list|get|create|update|delete|enable|disable"))
+	}
+	fs := c.flags("records " + args[0])
+	subcommand := args[0]
+	args = args[1:]
+
+	domainID := fs.String("domain-id", "", "domain ID")
+	switch subcommand {
+	case "list":
+		query := dnspod.RecordQuery{}
+		fs.StringVar(&query.SubDomain, "sub-domain", "", "only list the records of this sub domain")
+		fs.StringVar(&query.Keyword, "keyword", "", "search keyword")
+		if err := c.parse(fs, args, "domain-id"); err != nil {
+			return err
+		}
+		query.DomainID = *domainID
+		records, _, err := c.client.Domains.ListRecords(query)
+		if dnspod.IsNoRecords(err) {
+			return c.out.records([]dnspod.Record{})
+		}
+		if err != nil {
+			return err
+		}
+		return c.out.records(records.List)
+
+	case "get":
+		id := fs.String("id", "", "record ID")
+		if err := c.parse(fs, args, "domain-id", "id"); err != nil {
+			return err
+		}
+		record, _, err := c.client.Domains.GetRecord(*domainID, *id)
+		if err != nil {
+			return err
+		}
+		return c.out.records([]dnspod.Record{record})
+
+	case "create":
+		record := dnspod.Record{}
+		recordFlags(fs, &record)
+		if err := c.parse(fs, args, "domain-id", "name", "type", "value"); err != nil {
+			return err
+		}
+		if record.Line == "" && record.LineID == "" {
+			record.Line = "默认"
+		}
+		record, _, err := c.client.Domains.CreateRecord(*domainID, record)
+		if err != nil {
+			return err
+		}
+		return c.out.records([]dnspod.Record{record})
+
+	case "update":
+		id := fs.String("id", "", "record ID")
+		record := dnspod.Record{}
+		recordFlags(fs, &record)
+		if err := c.parse(fs, args, "domain-id", "id"); err != nil {
+			return err
+		}
+		record, _, err := c.client.Domains.UpdateRecord(*domainID, *id, record)
+		if err != nil {
+			return err
+		}
+		return c.out.records([]dnspod.Record{record})
+
+	case "delete":
+		id := fs.String("id", "", "record ID")
+		if err := c.parse(fs, args, "domain-id", "id"); err != nil {
+			return err
+		}
+		_, err := c.client.Domains.DeleteRecord(*domainID, *id)
+		return err
+
+	case "enable", "disable":
+		id := fs.String("id", "", "record ID")
+		if err := c.parse(fs, args, "domain-id", "id"); err != nil {
+			return err
+		}
+		_, err := c.client.Domains.UpdateRecordStatus(*domainID, *id, subcommand)
+		return err
+	}
+	return usageError(c.stderr, fmt.Errorf("unknown subcommand %q", subcommand))
+}
+
+func (c *command) lines(args []string) error {
+	fs := c.flags("lines")
+	domainID := fs.String("domain-id", "", "domain ID")
+	grade := fs.String("grade", "DP_Free", "domain grade")
+	if err := c.parse(fs, args, "domain-id"); err != nil {
+		return err
+	}
+	lines, _, err := c.client.Domains.GetRecordLine(*grade, *domainID)
+	if err != nil {
+		return err
+	}
+	return c.out.lines(lines)
+}
+
+func (c *command) user(args []string) error {
+	fs := c.flags("user")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	user, _, err := c.client.Users.Detail()
+	if err != nil {
+		return err
+	}
+	return c.out.user(user)
+}
+
+func (c *command) drift(args []string) error {
+	fs := c.flags("drift")
+	domainID := fs.String("domain-id", "", "domain ID")
+	file := fs.String("file", "", "source of truth: a zone configuration or file, or a YAML or JSON list of records")
+	domain := fs.String("domain", "", "domain name, for zone configurations and zone files without $ORIGIN")
+	junit := fs.String("junit", "", "also write the report as JUnit XML to this file")
+	if err := c.parse(fs, args, "domain-id", "file"); err != nil {
+		return err
+	}
+	desired, err := loadRecords(*file, *domain)
+	if err != nil {
+		return err
+	}
+	report, err := c.client.Domains.DriftReport(*domainID, desired)
+	if err != nil {
+		return err
+	}
+	if *junit != "" {
+		var b bytes.Buffer
+		if err := report.WriteJUnit(&b); err != nil {
+			return err
+		}
+		if err := os.WriteFile(*junit, b.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	if err := c.out.drift(report); err != nil {
+		return err
+	}
+	if report.HasDrift() {
+		return fmt.Errorf("domain %s drifted from %s: %d differences", *domainID, *file, len(report.Drifts))
+	}
+	return nil
+}
+
+// loadRecords reads records from a zone configuration, a YAML or JSON list of records
+// using the JSON field names, or a zone file.
+func loadRecords(path, domain string) ([]dnspod.Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		records, err := dnspod.ParseZoneFile(bytes.NewReader(data), domain)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return records, nil
+	}
+
+	// A mapping is a zone configuration holding the domain.
+	if node := (yaml.Node{}); yaml.Unmarshal(data, &node) == nil && len(node.Content) > 0 && node.Content[0].Kind == yaml.MappingNode {
+		config, err := zoneconfig.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		d, ok := config.Domain(domain)
+		if !ok {
+			return nil, fmt.Errorf("%s: no domain %q, set -domain", path, domain)
+		}
+		return d.DNSPodRecords(), nil
+	}
+
+	// YAML being a superset of JSON, both are read as YAML, the scalars as strings.
+	var generic []map[string]interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for _, fields := range generic {
+		for k, v := range fields {
+			switch v.(type) {
+			case string:
+			case nil:
+				delete(fields, k)
+			default:
+				fields[k] = fmt.Sprint(v)
+			}
+		}
+	}
+	bs, err := json.Marshal(generic)
+	if err != nil {
+		return nil, err
+	}
+	var records []dnspod.Record
+	if err := json.Unmarshal(bs, &records); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return records, nil
+}
+
+func (c *command) zones(args []string) error {
+	if len(args) == 0 || args[0] != "dump" {
+		return usageError(c.stderr, fmt.Errorf("usage: dnspodctl zones dump"))
+	}
+	fs := c.flags("zones dump")
+	domains := fs.String("domains", "", "comma separated domains to dump, all the account ones if empty")
+	if err := c.parse(fs, args[1:]); err != nil {
+		return err
+	}
+	var names []string
+	for _, name := range strings.Split(*domains, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	config, err := zoneconfig.Dump(c.client, names...)
+	if err != nil {
+		return err
+	}
+	if c.out.format == "json" {
+		return config.WriteJSON(c.out.w)
+	}
+	return config.WriteYAML(c.out.w)
+}
@@ -0,0 +1,186 @@
+// Command dnspodctl inspects and changes dnspod domains and records from a terminal.
+//
+// Usage:
+//
+//	dnspodctl [-o table|json|yaml] [-config file] <command> <subcommand> [flags]
+//
+// Commands:
+//
+//	domains list|get|create|delete|status
+//	records list|get|create|update|delete|enable|disable
+//	lines
+//	user
+//	drift -domain-id id -file source [-domain name] [-junit report.xml]
+//	zones dump [-domains example.com,example.net]
+//
+// The API token ("ID,Token") is read from the -token flag, the DNSPOD_TOKEN environment
+// variable or the config file, in that order. The config file defaults to
+// $HOME/.config/dnspodctl/config.yaml and may also set lang, user_id and base_url,
+// the latter being overridden by the DNSPOD_BASE_URL environment variable.
+//
+// drift compares the records of a domain with a source of truth, a zone configuration,
+// a zone file or a YAML or JSON list of records as output by "records list", and fails
+// when they differ. zones dump writes the zone configuration of the domains, in YAML
+// unless -o json is set.
+//
+// Exit status is 0 on success, 2 on usage errors and 1 on other errors, drift included.
+// When dnspod rejects an action, the exit status is derived from its status code, printed
+// along with the error: positive codes map to 10 + code (up to 99), negative codes
+// to 100 + |code| (up to 125) and codes that are not numbers to 3.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/decker502/dnspod-go"
+	"gopkg.in/yaml.v3"
+)
+
+// errUsage is returned for invalid command lines; the usage has already been printed.
+var errUsage = errors.New("usage error")
+
+// config is the content of the config file.
+type config struct {
+	Token   string `yaml:"token"`
+	Lang    string `yaml:"lang"`
+	UserID  string `yaml:"user_id"`
+	BaseURL string `yaml:"base_url"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes a command line and returns the process exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	err := execute(args, stdout, stderr)
+	if err != nil && err != errUsage {
+		fmt.Fprintln(stderr, "dnspodctl:", err)
+	}
+	return exitCode(err)
+}
+
+func execute(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("dnspodctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("o", "table", "output format: table, json or yaml")
+	configPath := fs.String("config", defaultConfigPath(), "config file")
+	token := fs.String("token", "", "API token, \"ID,Token\"")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: dnspodctl [flags] domains|records|lines|user|drift|zones ...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	out, err := newPrinter(*format, stdout)
+	if err != nil {
+		return usageError(stderr, err)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if env := os.Getenv("DNSPOD_TOKEN"); env != "" {
+		cfg.Token = env
+	}
+	if *token != "" {
+		cfg.Token = *token
+	}
+	if env := os.Getenv("DNSPOD_BASE_URL"); env != "" {
+		cfg.BaseURL = env
+	}
+	if cfg.Token == "" {
+		return usageError(stderr, errors.New("no API token: set -token, DNSPOD_TOKEN or the config file"))
+	}
+
+	client := dnspod.NewClient(dnspod.CommonParams{LoginToken: cfg.Token, Format: "json", Lang: cfg.Lang, UserID: cfg.UserID})
+	if cfg.BaseURL != "" {
+		client.BaseURL = cfg.BaseURL
+	}
+
+	cmd := &command{client: client, out: out, stderr: stderr}
+	rest := fs.Args()[1:]
+	switch fs.Arg(0) {
+	case "domains":
+		return cmd.domains(rest)
+	case "records":
+		return cmd.records(rest)
+	case "lines":
+		return cmd.lines(rest)
+	case "user":
+		return cmd.user(rest)
+	case "drift":
+		return cmd.drift(rest)
+	case "zones":
+		return cmd.zones(rest)
+	}
+	return usageError(stderr, fmt.Errorf("unknown command %q", fs.Arg(0)))
+}
+
+func usageError(stderr io.Writer, err error) error {
+	fmt.Fprintln(stderr, "dnspodctl:", err)
+	return errUsage
+}
+
+func defaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "dnspodctl", "config.yaml")
+}
+
+// loadConfig reads the config file, a missing file being an empty config.
+func loadConfig(path string) (config, error) {
+	cfg := config{}
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// exitCode derives the process exit status from an error.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if err == errUsage {
+		return 2
+	}
+	var apiErr *dnspod.APIError
+	if errors.As(err, &apiErr) {
+		code, convErr := strconv.Atoi(apiErr.Status.Code)
+		switch {
+		case convErr != nil || code == 0:
+			return 3
+		case code > 0:
+			return min(10+code, 99)
+		default:
+			return min(100-code, 125)
+		}
+	}
+	return 1
+}
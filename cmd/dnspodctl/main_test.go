@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/decker502/dnspod-go"
+)
+
+// runAgainst runs dnspodctl against a test server serving handler.
+func runAgainst(t *testing.T, handler http.HandlerFunc, args ...string) (int, string, string) {
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	t.Setenv("DNSPOD_TOKEN", "1,token")
+	t.Setenv("DNSPOD_BASE_URL", server.URL+"/")
+
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"-config", ""}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRecordsList(t *testing.T) {
+	code, stdout, stderr := runAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Record.List" || r.FormValue("domain_id") != "42" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Form)
+		}
+		fmt.Fprint(w, `{"status": {"code":"1"},"records":[{"id":"1","name":"www","type":"A","value":"1.2.3.4"}]}`)
+	}, "-o", "json", "records", "list", "-domain-id", "42")
+
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	if !strings.Contains(stdout, `"name": "www"`) {
+		t.Errorf("unexpected output %q", stdout)
+	}
+}
+
+func TestRecordsList_noRecords(t *testing.T) {
+	code, stdout, stderr := runAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"10","message":"No records"}}`)
+	}, "-o", "json", "records", "list", "-domain-id", "42")
+
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	if strings.TrimSpace(stdout) != "[]" {
+		t.Errorf("unexpected output %q", stdout)
+	}
+}
+
+func TestRecordsList_yaml(t *testing.T) {
+	_, stdout, _ := runAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"records":[{"id":"1","name":"www","type":"A","value":"1.2.3.4"}]}`)
+	}, "-o", "yaml", "records", "list", "-domain-id", "42")
+
+	if !strings.Contains(stdout, "- id: \"1\"") {
+		t.Errorf("unexpected output %q", stdout)
+	}
+}
+
+func TestAPIErrorExitCode(t *testing.T) {
+	code, _, stderr := runAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"-1","message":"Login failed"}}`)
+	}, "user")
+
+	if code != 101 {
+		t.Errorf("exit code %d, want 101", code)
+	}
+	if !strings.Contains(stderr, "Login failed") {
+		t.Errorf("stderr %q does not report the error", stderr)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	code, _, _ := runAgainst(t, nil, "records", "get", "-domain-id", "42")
+	if code != 2 {
+		t.Errorf("exit code %d, want 2", code)
+	}
+}
+
+func TestUsage_domainID(t *testing.T) {
+	for _, args := range [][]string{
+		{"domains", "delete"},
+		{"domains", "delete", "-id", "0"},
+		{"domains", "get"},
+	} {
+		code, _, stderr := runAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("%v: unexpected request %s", args, r.URL.Path)
+		}, args...)
+		if code != 2 || !strings.Contains(stderr, "-id is required") {
+			t.Errorf("%v: exit code %d, stderr %q", args, code, stderr)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want int
+	}{
+		{"1", 11},
+		{"2", 12},
+		{"6", 16},
+		{"-8", 108},
+		{"500", 99},
+		{"-99", 125},
+		{"", 3},
+	}
+	for _, tt := range tests {
+		err := &dnspod.APIError{Status: dnspod.Status{Code: tt.code}}
+		if got := exitCode(err); got != tt.want {
+			t.Errorf("exitCode(%q) = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestDrift(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "example.com.yaml")
+	os.WriteFile(source, []byte("- name: www\n  type: A\n  value: 1.1.1.1\n  ttl: 600\n- name: new\n  type: A\n  value: 2.2.2.2\n"), 0o644)
+	junit := filepath.Join(dir, "drift.xml")
+
+	code, stdout, stderr := runAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"info": {"record_total": "1"},"records":[{"id":"1","name":"www","type":"A","value":"1.1.1.1","line":"默认","ttl":"600"}]}`)
+	}, "drift", "-domain-id", "42", "-file", source, "-junit", junit)
+
+	if code != 1 || !strings.Contains(stderr, "1 differences") {
+		t.Errorf("exit code %d, stderr %q", code, stderr)
+	}
+	if !strings.Contains(stdout, "missing") || !strings.Contains(stdout, "new") {
+		t.Errorf("unexpected output %q", stdout)
+	}
+	if data, err := os.ReadFile(junit); err != nil || !strings.Contains(string(data), `failures="1"`) {
+		t.Errorf("JUnit report %q, %v", data, err)
+	}
+}
+
+func TestZonesDump(t *testing.T) {
+	code, stdout, stderr := runAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Domain.Grouplist":
+			fmt.Fprint(w, `{"status": {"code":"1"},"groups":[{"group_id":1,"group_name":"默认分组","group_type":"system"}]}`)
+		case "/Domain.List":
+			fmt.Fprint(w, `{"status": {"code":"1"},"info":{"domain_total":1},"domains":[{"id":42,"name":"example.com","group_id":"1"}]}`)
+		case "/Record.List":
+			fmt.Fprint(w, `{"status": {"code":"1"},"info": {"record_total": "1"},"records":[{"id":"1","name":"www","type":"A","value":"1.1.1.1","line":"默认","ttl":"600","enabled":"1"}]}`)
+		}
+	}, "zones", "dump")
+
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	if !strings.HasPrefix(stdout, "version: 1\n") || !strings.Contains(stdout, "- name: www\n        type: A\n        value: 1.1.1.1\n        ttl: 600\n") {
+		t.Errorf("unexpected output %q", stdout)
+	}
+}
+
+func TestDrift_zoneConfig(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "zones.yaml")
+	os.WriteFile(source, []byte("version: 1\ndomains:\n  - name: example.com\n    records:\n      - {name: www, type: A, value: 1.1.1.1, ttl: 600}\n"), 0o644)
+
+	code, _, stderr := runAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"info": {"record_total": "1"},"records":[{"id":"1","name":"www","type":"A","value":"1.1.1.1","line":"默认","ttl":"600"}]}`)
+	}, "drift", "-domain-id", "42", "-domain", "example.com", "-file", source)
+
+	if code != 0 {
+		t.Errorf("exit code %d, stderr %q", code, stderr)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/decker502/dnspod-go"
+	"gopkg.in/yaml.v3"
+)
+
+// printer renders command results in the selected output format.
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "table", "json", "yaml":
+		return printer{format: format, w: w}, nil
+	}
+	return printer{}, fmt.Errorf("unknown output format %q", format)
+}
+
+// structured writes v as JSON or YAML, using the JSON field names in both cases.
+func (p printer) structured(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if p.format == "json" {
+		_, err = fmt.Fprintf(p.w, "%s\n", data)
+		return err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(p.w)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// table writes a header and rows as aligned columns.
+func (p printer) table(header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	for i, column := range header {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, column)
+	}
+	fmt.Fprintln(tw)
+	for _, row := range rows {
+		for i, cell := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func (p printer) domains(domains []dnspod.Domain) error {
+	if p.format != "table" {
+		return p.structured(domains)
+	}
+	var rows [][]string
+	for _, d := range domains {
+		rows = append(rows, []string{d.ID, d.Name, d.Grade, d.Status, d.ExtStatus, d.Records, d.UpdatedOn})
+	}
+	return p.table([]string{"ID", "NAME", "GRADE", "STATUS", "EXT_STATUS", "RECORDS", "UPDATED_ON"}, rows)
+}
+
+func (p printer) records(records []dnspod.Record) error {
+	if p.format != "table" {
+		return p.structured(records)
+	}
+	var rows [][]string
+	for _, r := range records {
+		rows = append(rows, []string{r.ID, r.Name, r.Type, r.Line, r.Value, r.MX, r.TTL, r.Enabled, r.Status})
+	}
+	return p.table([]string{"ID", "NAME", "TYPE", "LINE", "VALUE", "MX", "TTL", "ENABLED", "STATUS"}, rows)
+}
+
+func (p printer) lines(lines []dnspod.RecordLine) error {
+	if p.format != "table" {
+		return p.structured(lines)
+	}
+	var rows [][]string
+	for _, l := range lines {
+		rows = append(rows, []string{l.LineID, l.Line})
+	}
+	return p.table([]string{"LINE_ID", "LINE"}, rows)
+}
+
+func (p printer) user(user dnspod.User) error {
+	if p.format != "table" {
+		return p.structured(user)
+	}
+	return p.table([]string{"ID", "EMAIL", "NICK", "TYPE", "GRADE", "STATUS"},
+		[][]string{{user.ID, user.Email, user.Nick, user.UserType, user.UserGrade, user.Status}})
+}
+
+func (p printer) drift(report *dnspod.DriftReport) error {
+	if p.format != "table" {
+		return p.structured(report)
+	}
+	var rows [][]string
+	for _, d := range report.Drifts {
+		rows = append(rows, []string{string(d.Kind), d.Name, d.Type, d.Line, d.Detail})
+	}
+	return p.table([]string{"KIND", "NAME", "TYPE", "LINE", "DETAIL"}, rows)
+}
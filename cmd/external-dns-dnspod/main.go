@@ -0,0 +1,92 @@
+// Command external-dns-dnspod is an external-dns webhook provider serving dnspod domains.
+//
+// Usage:
+//
+//	external-dns-dnspod [-listen addr] [-domain-filter example.com,example.net] [-min-ttl seconds]
+//
+// It runs as a sidecar of external-dns started with --provider=webhook, and serves
+// the webhook protocol on -listen (localhost:8888 by default) along with /healthz.
+// The API token ("ID,Token") is read from the -token flag or the DNSPOD_TOKEN environment
+// variable, and the API base URL may be overridden by DNSPOD_BASE_URL.
+//
+// Records are managed on the default line, including the TXT ownership records of
+// external-dns.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/decker502/dnspod-go"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if err := run(os.Args[1:], os.Stderr, logger); err != nil {
+		logger.Error("external-dns-dnspod failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stderr io.Writer, logger *slog.Logger) error {
+	fs := flag.NewFlagSet("external-dns-dnspod", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	listen := fs.String("listen", "localhost:8888", "address of the webhook server")
+	filter := fs.String("domain-filter", "", "comma separated domains to manage, all the account ones if empty")
+	minTTL := fs.Int64("min-ttl", 600, "minimum TTL of the domain grade, in seconds")
+	token := fs.String("token", os.Getenv("DNSPOD_TOKEN"), "API token, \"ID,Token\"")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *token == "" {
+		return errors.New("no API token: set -token or DNSPOD_TOKEN")
+	}
+
+	client := dnspod.NewClient(dnspod.CommonParams{LoginToken: *token, Format: "json"})
+	if env := os.Getenv("DNSPOD_BASE_URL"); env != "" {
+		client.BaseURL = env
+	}
+	client.Logger = logger
+
+	wh := &webhook{client: client, domains: splitDomains(*filter), minTTL: *minTTL, logger: logger}
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           wh.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	logger.Info("serving the external-dns webhook", "listen", *listen, "domains", wh.domains)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("serve %s: %v", *listen, err)
+	}
+	return nil
+}
+
+func splitDomains(s string) []string {
+	var domains []string
+	for _, d := range strings.Split(s, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
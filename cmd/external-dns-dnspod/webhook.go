@@ -0,0 +1,399 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decker502/dnspod-go"
+)
+
+// mediaType is the content type of the external-dns webhook protocol.
+const mediaType = "application/external.dns.webhook+json;version=1"
+
+// endpoint is an external-dns endpoint: the records sharing a name and type.
+type endpoint struct {
+	DNSName          string             `json:"dnsName"`
+	Targets          []string           `json:"targets"`
+	RecordType       string             `json:"recordType"`
+	SetIdentifier    string             `json:"setIdentifier,omitempty"`
+	RecordTTL        int64              `json:"recordTTL,omitempty"`
+	Labels           map[string]string  `json:"labels,omitempty"`
+	ProviderSpecific []providerSpecific `json:"providerSpecific,omitempty"`
+}
+
+type providerSpecific struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// changes is the body of the apply changes request.
+type changes struct {
+	Create    []*endpoint `json:"Create"`
+	UpdateOld []*endpoint `json:"UpdateOld"`
+	UpdateNew []*endpoint `json:"UpdateNew"`
+	Delete    []*endpoint `json:"Delete"`
+}
+
+// domainFilter is the body of the negotiation response.
+type domainFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// supportedTypes are the record types managed by the webhook.
+var supportedTypes = map[string]bool{"A": true, "AAAA": true, "CNAME": true, "TXT": true, "MX": true, "SRV": true, "NS": true, "CAA": true}
+
+// webhook serves the external-dns webhook protocol on top of a dnspod client.
+// Only the records of the default line are managed.
+type webhook struct {
+	client  *dnspod.Client
+	domains []string // managed domains, all the account ones if empty
+	minTTL  int64
+	logger  *slog.Logger
+}
+
+func (wh *webhook) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", wh.negotiate)
+	mux.HandleFunc("GET /records", wh.records)
+	mux.HandleFunc("POST /records", wh.applyChanges)
+	mux.HandleFunc("POST /adjustendpoints", wh.adjustEndpoints)
+	mux.HandleFunc("GET /healthz", wh.healthz)
+	return mux
+}
+
+func (wh *webhook) negotiate(w http.ResponseWriter, r *http.Request) {
+	wh.reply(w, domainFilter{Include: wh.domains})
+}
+
+func (wh *webhook) records(w http.ResponseWriter, r *http.Request) {
+	zones, err := wh.zones(r.Context())
+	if err != nil {
+		wh.fail(w, "list domains", err)
+		return
+	}
+
+	endpoints := []*endpoint{}
+	for _, z := range zones {
+		records, err := wh.client.Domains.ListAllRecordsContext(r.Context(), z.ID)
+		if err != nil {
+			wh.fail(w, "list records of "+z.Name, err)
+			return
+		}
+		endpoints = append(endpoints, toEndpoints(z.Name, records)...)
+	}
+	wh.reply(w, endpoints)
+}
+
+func (wh *webhook) adjustEndpoints(w http.ResponseWriter, r *http.Request) {
+	var endpoints []*endpoint
+	if err := json.NewDecoder(r.Body).Decode(&endpoints); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, e := range endpoints {
+		wh.adjust(e)
+	}
+	wh.reply(w, endpoints)
+}
+
+// adjust normalizes an endpoint the way Records returns it, so that external-dns
+// doesn't plan changes dnspod would not keep.
+func (wh *webhook) adjust(e *endpoint) {
+	e.DNSName = strings.ToLower(strings.TrimSuffix(e.DNSName, "."))
+	if e.RecordTTL > 0 && e.RecordTTL < wh.minTTL {
+		e.RecordTTL = wh.minTTL
+	}
+	for i, target := range e.Targets {
+		e.Targets[i] = normalizeTarget(e.RecordType, target)
+	}
+}
+
+func (wh *webhook) applyChanges(w http.ResponseWriter, r *http.Request) {
+	var c changes
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(c.UpdateOld) != len(c.UpdateNew) {
+		http.Error(w, "UpdateOld and UpdateNew differ in length", http.StatusBadRequest)
+		return
+	}
+
+	zones, err := wh.zones(r.Context())
+	if err != nil {
+		wh.fail(w, "list domains", err)
+		return
+	}
+	plan := &changePlan{ctx: r.Context(), zones: zones, current: map[string][]dnspod.Record{}, client: wh.client}
+
+	// Deletions come first so that e.g. a CNAME can replace an A record.
+	var ops []dnspod.RecordOperation
+	for _, e := range c.Delete {
+		more, err := plan.delete(e)
+		if err != nil {
+			wh.fail(w, "plan deletions", err)
+			return
+		}
+		ops = append(ops, more...)
+	}
+	for i := range c.UpdateNew {
+		more, err := plan.update(c.UpdateOld[i], c.UpdateNew[i])
+		if err != nil {
+			wh.fail(w, "plan updates", err)
+			return
+		}
+		ops = append(ops, more...)
+	}
+	for _, e := range c.Create {
+		more, err := plan.create(e)
+		if err != nil {
+			wh.fail(w, "plan creations", err)
+			return
+		}
+		ops = append(ops, more...)
+	}
+
+	if len(ops) > 0 {
+		if _, err := wh.client.Domains.Bulk(r.Context(), ops, dnspod.BulkOptions{StopOnError: true}); err != nil {
+			wh.fail(w, "apply changes", err)
+			return
+		}
+	}
+	wh.logger.Info("applied changes", "create", len(c.Create), "update", len(c.UpdateNew), "delete", len(c.Delete), "operations", len(ops))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (wh *webhook) healthz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	if _, err := wh.client.Ping(ctx); err != nil {
+		wh.fail(w, "ping", err)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func (wh *webhook) reply(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", mediaType)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		wh.logger.Error("write response", "error", err)
+	}
+}
+
+func (wh *webhook) fail(w http.ResponseWriter, msg string, err error) {
+	wh.logger.Error(msg, "error", err)
+	http.Error(w, msg+": "+err.Error(), http.StatusInternalServerError)
+}
+
+// zone is a managed dnspod domain.
+type zone struct {
+	ID   string
+	Name string
+}
+
+// zones lists the managed domains, longest names first so that the first
+// suffix match of a name is its most specific zone.
+func (wh *webhook) zones(ctx context.Context) ([]zone, error) {
+	domains, err := wh.client.Domains.ListAllDomainsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	managed := map[string]bool{}
+	for _, d := range wh.domains {
+		managed[strings.ToLower(strings.TrimSuffix(d, "."))] = true
+	}
+
+	var zones []zone
+	for _, d := range domains {
+		name := strings.ToLower(d.Name)
+		if len(managed) == 0 || managed[name] {
+			zones = append(zones, zone{ID: d.ID, Name: name})
+		}
+	}
+	sort.Slice(zones, func(i, j int) bool { return len(zones[i].Name) > len(zones[j].Name) })
+	return zones, nil
+}
+
+// toEndpoints groups the enabled records of the default line of a zone by name and type.
+func toEndpoints(zoneName string, records []dnspod.Record) []*endpoint {
+	var endpoints []*endpoint
+	index := map[string]*endpoint{}
+	for _, r := range records {
+		if r.Line != "" && r.Line != dnspod.DefaultLine || !supportedTypes[r.Type] || !dnspod.RecordEnabled(r) {
+			continue
+		}
+		if r.Name == "@" && r.Type == "NS" {
+			continue
+		}
+		name := zoneName
+		if r.Name != "@" {
+			name = r.Name + "." + zoneName
+		}
+		key := name + "|" + r.Type
+		e, ok := index[key]
+		if !ok {
+			ttl, _ := strconv.ParseInt(r.TTL, 10, 64)
+			e = &endpoint{DNSName: name, RecordType: r.Type, RecordTTL: ttl}
+			index[key] = e
+			endpoints = append(endpoints, e)
+		}
+		e.Targets = append(e.Targets, recordTarget(r))
+	}
+	return endpoints
+}
+
+// recordTarget returns the endpoint target of a record.
+func recordTarget(r dnspod.Record) string {
+	value := r.Value
+	if r.Type == "MX" {
+		value = r.MX + " " + value
+	}
+	return normalizeTarget(r.Type, value)
+}
+
+// normalizeTarget strips the trailing dots of host names and the quotes of TXT
+// values, which dnspod doesn't keep consistently.
+func normalizeTarget(recordType, target string) string {
+	switch recordType {
+	case "TXT":
+		if len(target) >= 2 && strings.HasPrefix(target, `"`) && strings.HasSuffix(target, `"`) {
+			return target[1 : len(target)-1]
+		}
+	case "CNAME", "MX", "NS", "SRV":
+		return strings.TrimSuffix(target, ".")
+	}
+	return target
+}
+
+// changePlan turns endpoint changes into record operations, listing the
+// records of each zone at most once, within the context of the request.
+type changePlan struct {
+	ctx     context.Context
+	client  *dnspod.Client
+	zones   []zone
+	current map[string][]dnspod.Record
+}
+
+// locate returns the zone and relative name of an endpoint.
+func (p *changePlan) locate(e *endpoint) (zone, string, error) {
+	name := strings.ToLower(strings.TrimSuffix(e.DNSName, "."))
+	for _, z := range p.zones {
+		if name == z.Name {
+			return z, "@", nil
+		}
+		if strings.HasSuffix(name, "."+z.Name) {
+			return z, strings.TrimSuffix(name, "."+z.Name), nil
+		}
+	}
+	return zone{}, "", fmt.Errorf("no managed domain for %s", e.DNSName)
+}
+
+// records returns the records of an endpoint name and type.
+func (p *changePlan) records(z zone, name, recordType string) ([]dnspod.Record, error) {
+	records, ok := p.current[z.ID]
+	if !ok {
+		var err error
+		if records, err = p.client.Domains.ListAllRecordsContext(p.ctx, z.ID); err != nil {
+			return nil, err
+		}
+		p.current[z.ID] = records
+	}
+	var matching []dnspod.Record
+	for _, r := range records {
+		if strings.EqualFold(r.Name, name) && r.Type == recordType && (r.Line == "" || r.Line == dnspod.DefaultLine) {
+			matching = append(matching, r)
+		}
+	}
+	return matching, nil
+}
+
+// toRecords returns the records of an endpoint, one per target.
+func toRecords(e *endpoint, name string) ([]dnspod.Record, error) {
+	var records []dnspod.Record
+	for _, target := range e.Targets {
+		r := dnspod.Record{Name: name, Type: e.RecordType, Line: dnspod.DefaultLine, Value: normalizeTarget(e.RecordType, target)}
+		if e.RecordTTL > 0 {
+			r.TTL = strconv.FormatInt(e.RecordTTL, 10)
+		}
+		if e.RecordType == "MX" {
+			preference, host, ok := strings.Cut(r.Value, " ")
+			if !ok {
+				return nil, fmt.Errorf("invalid MX target %q of %s", target, e.DNSName)
+			}
+			r.MX, r.Value = preference, host
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (p *changePlan) create(e *endpoint) ([]dnspod.RecordOperation, error) {
+	z, name, err := p.locate(e)
+	if err != nil {
+		return nil, err
+	}
+	current, err := p.records(z, name, e.RecordType)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := toRecords(e, name)
+	if err != nil {
+		return nil, err
+	}
+	// Disabled records, left out of Records, are enabled rather than created again,
+	// which dnspod would reject.
+	for i := range current {
+		current[i].Value = normalizeTarget(current[i].Type, current[i].Value)
+	}
+	return dnspod.DiffRecords(z.ID, current, desired, true), nil
+}
+
+func (p *changePlan) update(old, new *endpoint) ([]dnspod.RecordOperation, error) {
+	z, name, err := p.locate(new)
+	if err != nil {
+		return nil, err
+	}
+	current, err := p.records(z, name, old.RecordType)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := toRecords(new, name)
+	if err != nil {
+		return nil, err
+	}
+	// Compare targets the way Records reports them.
+	for i := range current {
+		current[i].Value = normalizeTarget(current[i].Type, current[i].Value)
+	}
+	return dnspod.DiffRecords(z.ID, current, desired, false), nil
+}
+
+func (p *changePlan) delete(e *endpoint) ([]dnspod.RecordOperation, error) {
+	z, name, err := p.locate(e)
+	if err != nil {
+		return nil, err
+	}
+	current, err := p.records(z, name, e.RecordType)
+	if err != nil {
+		return nil, err
+	}
+	targets := map[string]bool{}
+	for _, target := range e.Targets {
+		targets[normalizeTarget(e.RecordType, target)] = true
+	}
+	var ops []dnspod.RecordOperation
+	for _, r := range current {
+		if targets[recordTarget(r)] {
+			ops = append(ops, dnspod.RecordOperation{Kind: dnspod.RecordDelete, DomainID: z.ID, RecordID: r.ID})
+		}
+	}
+	return ops, nil
+}
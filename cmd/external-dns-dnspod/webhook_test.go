@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/decker502/dnspod-go"
+	"github.com/decker502/dnspod-go/dnspodtest"
+)
+
+// newWebhook serves the webhook against a fake dnspod holding example.com.
+func newWebhook(t *testing.T, records ...dnspod.Record) (*httptest.Server, *dnspodtest.Server, string) {
+	t.Helper()
+	srv := dnspodtest.NewServer()
+	t.Cleanup(srv.Close)
+	id := srv.AddDomain("example.com", records...)
+	srv.AddDomain("example.net")
+
+	wh := &webhook{client: srv.Client(), domains: []string{"example.com"}, minTTL: 600, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	server := httptest.NewServer(wh.handler())
+	t.Cleanup(server.Close)
+	return server, srv, id
+}
+
+func call(t *testing.T, method, url string, body, v interface{}) *http.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, url, &buf)
+	req.Header.Set("Accept", mediaType)
+	req.Header.Set("Content-Type", mediaType)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer res.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: invalid response: %v", method, url, err)
+		}
+	}
+	return res
+}
+
+func sortTargets(endpoints []*endpoint) {
+	for _, e := range endpoints {
+		sort.Strings(e.Targets)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].DNSName != endpoints[j].DNSName {
+			return endpoints[i].DNSName < endpoints[j].DNSName
+		}
+		return endpoints[i].RecordType < endpoints[j].RecordType
+	})
+}
+
+func TestNegotiate(t *testing.T) {
+	server, _, _ := newWebhook(t)
+
+	var filter domainFilter
+	res := call(t, "GET", server.URL+"/", nil, &filter)
+	if got := res.Header.Get("Content-Type"); got != mediaType {
+		t.Errorf("Content-Type = %q, want %q", got, mediaType)
+	}
+	if !reflect.DeepEqual(filter.Include, []string{"example.com"}) {
+		t.Errorf("domain filter = %+v", filter)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	server, _, _ := newWebhook(t,
+		dnspod.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: "600"},
+		dnspod.Record{Name: "www", Type: "A", Value: "192.0.2.2", TTL: "600"},
+		dnspod.Record{Name: "a-www", Type: "TXT", Value: "heritage=external-dns,external-dns/owner=default", TTL: "600"},
+		dnspod.Record{Name: "@", Type: "MX", Value: "mx.example.com.", MX: "10", TTL: "3600"},
+		dnspod.Record{Name: "www", Type: "A", Line: "电信", Value: "192.0.2.9", TTL: "600"},
+	)
+
+	var endpoints []*endpoint
+	call(t, "GET", server.URL+"/records", nil, &endpoints)
+	sortTargets(endpoints)
+
+	want := []*endpoint{
+		{DNSName: "a-www.example.com", RecordType: "TXT", RecordTTL: 600, Targets: []string{"heritage=external-dns,external-dns/owner=default"}},
+		{DNSName: "example.com", RecordType: "MX", RecordTTL: 3600, Targets: []string{"10 mx.example.com"}},
+		{DNSName: "www.example.com", RecordType: "A", RecordTTL: 600, Targets: []string{"192.0.2.1", "192.0.2.2"}},
+	}
+	if !reflect.DeepEqual(endpoints, want) {
+		got, _ := json.Marshal(endpoints)
+		t.Errorf("records = %s", got)
+	}
+}
+
+func TestRecords_paginated(t *testing.T) {
+	srv := dnspodtest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddDomain("example.com")
+	srv.AddDomain("example.net", dnspod.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: "600"})
+	srv.PageLimit = 1
+
+	wh := &webhook{client: srv.Client(), domains: []string{"example.net"}, minTTL: 600, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	server := httptest.NewServer(wh.handler())
+	t.Cleanup(server.Close)
+
+	var endpoints []*endpoint
+	call(t, "GET", server.URL+"/records", nil, &endpoints)
+	want := []*endpoint{{DNSName: "www.example.net", RecordType: "A", RecordTTL: 600, Targets: []string{"192.0.2.1"}}}
+	if !reflect.DeepEqual(endpoints, want) {
+		got, _ := json.Marshal(endpoints)
+		t.Errorf("records = %s", got)
+	}
+}
+
+func TestRecords_canceled(t *testing.T) {
+	srv := dnspodtest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddDomain("example.com")
+	wh := &webhook{client: srv.Client(), domains: []string{"example.com"}, minTTL: 600, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/records", nil).WithContext(ctx)
+	req.Header.Set("Accept", mediaType)
+	rec := httptest.NewRecorder()
+	wh.handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("records of a disconnected client returned %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if n := srv.Calls("Domain.List") + srv.Calls("Record.List"); n != 0 {
+		t.Errorf("records of a disconnected client made %d calls", n)
+	}
+}
+
+func TestAdjustEndpoints(t *testing.T) {
+	server, _, _ := newWebhook(t)
+
+	var adjusted []*endpoint
+	call(t, "POST", server.URL+"/adjustendpoints", []*endpoint{
+		{DNSName: "WWW.example.com.", RecordType: "CNAME", RecordTTL: 60, Targets: []string{"lb.example.net."}},
+		{DNSName: "a-www.example.com", RecordType: "TXT", Targets: []string{`"heritage=external-dns"`}},
+	}, &adjusted)
+
+	want := []*endpoint{
+		{DNSName: "www.example.com", RecordType: "CNAME", RecordTTL: 600, Targets: []string{"lb.example.net"}},
+		{DNSName: "a-www.example.com", RecordType: "TXT", Targets: []string{"heritage=external-dns"}},
+	}
+	if !reflect.DeepEqual(adjusted, want) {
+		got, _ := json.Marshal(adjusted)
+		t.Errorf("adjusted endpoints = %s", got)
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	server, srv, id := newWebhook(t,
+		dnspod.Record{Name: "old", Type: "A", Value: "192.0.2.1", TTL: "600"},
+		dnspod.Record{Name: "a-old", Type: "TXT", Value: "heritage=external-dns,external-dns/owner=default", TTL: "600"},
+		dnspod.Record{Name: "api", Type: "A", Value: "192.0.2.2", TTL: "600"},
+		dnspod.Record{Name: "api", Type: "A", Value: "192.0.2.3", TTL: "600"},
+	)
+
+	res := call(t, "POST", server.URL+"/records", changes{
+		Create: []*endpoint{
+			{DNSName: "www.example.com", RecordType: "CNAME", RecordTTL: 600, Targets: []string{"lb.example.net"}},
+			{DNSName: "a-www.example.com", RecordType: "TXT", RecordTTL: 600, Targets: []string{`"heritage=external-dns,external-dns/owner=default"`}},
+		},
+		UpdateOld: []*endpoint{{DNSName: "api.example.com", RecordType: "A", Targets: []string{"192.0.2.2", "192.0.2.3"}}},
+		UpdateNew: []*endpoint{{DNSName: "api.example.com", RecordType: "A", RecordTTL: 600, Targets: []string{"192.0.2.3", "192.0.2.4"}}},
+		Delete: []*endpoint{
+			{DNSName: "old.example.com", RecordType: "A", Targets: []string{"192.0.2.1"}},
+			{DNSName: "a-old.example.com", RecordType: "TXT", Targets: []string{"heritage=external-dns,external-dns/owner=default"}},
+		},
+	}, nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("apply changes returned %s", res.Status)
+	}
+
+	var got []string
+	for _, r := range srv.Records(id) {
+		if r.Type != "NS" {
+			got = append(got, r.Name+" "+r.Type+" "+r.Value)
+		}
+	}
+	sort.Strings(got)
+	want := []string{
+		"a-www TXT heritage=external-dns,external-dns/owner=default",
+		"api A 192.0.2.3",
+		"api A 192.0.2.4",
+		"www CNAME lb.example.net",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %q, want %q", got, want)
+	}
+}
+
+func TestApplyChanges_disabledRecord(t *testing.T) {
+	server, srv, id := newWebhook(t,
+		dnspod.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: "600", Status: "disable"},
+	)
+
+	// The disabled record is not reported, so external-dns plans its creation.
+	var endpoints []*endpoint
+	call(t, "GET", server.URL+"/records", nil, &endpoints)
+	if len(endpoints) != 0 {
+		got, _ := json.Marshal(endpoints)
+		t.Fatalf("records = %s, want the disabled record left out", got)
+	}
+
+	res := call(t, "POST", server.URL+"/records", changes{
+		Create: []*endpoint{{DNSName: "www.example.com", RecordType: "A", RecordTTL: 600, Targets: []string{"192.0.2.1", "192.0.2.2"}}},
+	}, nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("apply changes returned %s", res.Status)
+	}
+
+	var got []string
+	for _, r := range srv.Records(id) {
+		if r.Name == "www" {
+			got = append(got, r.Value+" "+strconv.FormatBool(dnspod.RecordEnabled(r)))
+		}
+	}
+	sort.Strings(got)
+	if want := []string{"192.0.2.1 true", "192.0.2.2 true"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("www records = %q, want %q", got, want)
+	}
+}
+
+func TestApplyChanges_unmanagedDomain(t *testing.T) {
+	server, _, _ := newWebhook(t)
+
+	res := call(t, "POST", server.URL+"/records", changes{
+		Create: []*endpoint{{DNSName: "www.example.net", RecordType: "A", Targets: []string{"192.0.2.1"}}},
+	}, nil)
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("apply changes to an unmanaged domain returned %s", res.Status)
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	server, _, _ := newWebhook(t)
+
+	if res := call(t, "GET", server.URL+"/healthz", nil, nil); res.StatusCode != http.StatusOK {
+		t.Errorf("healthz returned %s", res.Status)
+	}
+}
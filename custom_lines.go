@@ -0,0 +1,321 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// CustomLinesService handles communication with the custom line related
+// methods of the dnspod API. Custom lines are only available to enterprise grades.
+type CustomLinesService struct {
+	client *Client
+}
+
+// LineGroupsService handles communication with the line group related
+// methods of the dnspod API. Line groups are only available to enterprise grades.
+type LineGroupsService struct {
+	client *Client
+}
+
+// CustomLine is a line matching the resolvers of the given IP ranges.
+type CustomLine struct {
+	ID     string         `json:"id,omitempty"`
+	Name   string         `json:"name,omitempty"`
+	Ranges []netip.Prefix `json:"ranges,omitempty"`
+}
+
+// LineGroup is a named set of lines a record may be attached to as a whole.
+type LineGroup struct {
+	ID    string   `json:"id,omitempty"`
+	Name  string   `json:"name,omitempty"`
+	Lines []string `json:"lines,omitempty"`
+}
+
+type customLine struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Area string `json:"area"`
+}
+
+type customLinesWrapper struct {
+	Status Status       `json:"status"`
+	Lines  []customLine `json:"lines"`
+}
+
+type customLineWrapper struct {
+	Status Status     `json:"status"`
+	Line   customLine `json:"line"`
+}
+
+type lineGroup struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Lines string `json:"lines"`
+}
+
+type lineGroupsWrapper struct {
+	Status Status      `json:"status"`
+	Groups []lineGroup `json:"line_groups"`
+}
+
+type lineGroupWrapper struct {
+	Status Status    `json:"status"`
+	Group  lineGroup `json:"line_group"`
+}
+
+// customLineAction generates the resource path for given custom line action.
+func customLineAction(action string) string {
+	if len(action) > 0 {
+		return fmt.Sprintf("Custom.Line.%s", action)
+	}
+	return "Custom.Line.List"
+}
+
+// lineGroupAction generates the resource path for given line group action.
+func lineGroupAction(action string) string {
+	if len(action) > 0 {
+		return fmt.Sprintf("Line.Group.%s", action)
+	}
+	return "Line.Group.List"
+}
+
+// FormatArea encodes IP ranges as the area parameter of custom lines.
+func FormatArea(ranges []netip.Prefix) string {
+	parts := make([]string, 0, len(ranges))
+	for _, p := range ranges {
+		parts = append(parts, p.Masked().String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseArea decodes the area of a custom line: a comma separated list
+// of addresses, CIDR prefixes and "first-last" address ranges.
+func ParseArea(area string) ([]netip.Prefix, error) {
+	var ranges []netip.Prefix
+	for _, part := range strings.FieldsFunc(area, func(r rune) bool { return r == ',' || r == '\n' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.Contains(part, "/") {
+			p, err := netip.ParsePrefix(part)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, p.Masked())
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := netip.ParseAddr(strings.TrimSpace(first))
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = netip.ParseAddr(strings.TrimSpace(last)); err != nil {
+				return nil, err
+			}
+		}
+		prefixes, err := rangePrefixes(from, to)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, prefixes...)
+	}
+	return ranges, nil
+}
+
+// rangePrefixes returns the smallest list of prefixes covering the addresses from first to last.
+func rangePrefixes(first, last netip.Addr) ([]netip.Prefix, error) {
+	if first.BitLen() != last.BitLen() || last.Less(first) {
+		return nil, fmt.Errorf("invalid address range %s-%s", first, last)
+	}
+	var prefixes []netip.Prefix
+	for {
+		bits := first.BitLen()
+		// Widen the prefix while it starts at first and stays within last.
+		for bits > 0 {
+			p, _ := first.Prefix(bits - 1)
+			if p.Addr() != first || lastAddr(p).Compare(last) > 0 {
+				break
+			}
+			bits--
+		}
+		p := netip.PrefixFrom(first, bits)
+		prefixes = append(prefixes, p)
+
+		end := lastAddr(p)
+		if end.Compare(last) >= 0 {
+			return prefixes, nil
+		}
+		first = end.Next()
+	}
+}
+
+// lastAddr returns the last address of a prefix.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - uint(i%8))
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+func (l customLine) customLine() (CustomLine, error) {
+	ranges, err := ParseArea(l.Area)
+	if err != nil {
+		return CustomLine{}, fmt.Errorf("invalid area of custom line %s: %v", l.Name, err)
+	}
+	return CustomLine{ID: l.ID, Name: l.Name, Ranges: ranges}, nil
+}
+
+func (g lineGroup) lineGroup() LineGroup {
+	group := LineGroup{ID: g.ID, Name: g.Name}
+	for _, l := range strings.Split(g.Lines, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			group.Lines = append(group.Lines, l)
+		}
+	}
+	return group
+}
+
+// List the custom lines of a domain.
+func (s *CustomLinesService) List(domainID string) ([]CustomLine, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+
+	wrapper := customLinesWrapper{}
+	res, err := s.client.post(customLineAction("List"), payload, &wrapper)
+	if err != nil {
+		return nil, res, err
+	}
+
+	lines := make([]CustomLine, 0, len(wrapper.Lines))
+	for _, l := range wrapper.Lines {
+		line, err := l.customLine()
+		if err != nil {
+			return nil, res, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, res, nil
+}
+
+// Create a custom line for a domain.
+func (s *CustomLinesService) Create(domainID string, line CustomLine) (CustomLine, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("name", line.Name)
+	payload.Set("area", FormatArea(line.Ranges))
+
+	return s.save(customLineAction("Create"), domainID, line, payload)
+}
+
+// Update a custom line of a domain.
+func (s *CustomLinesService) Update(domainID string, line CustomLine) (CustomLine, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("id", line.ID)
+	payload.Set("name", line.Name)
+	payload.Set("area", FormatArea(line.Ranges))
+
+	return s.save(customLineAction("Modify"), domainID, line, payload)
+}
+
+func (s *CustomLinesService) save(path, domainID string, line CustomLine, payload url.Values) (CustomLine, *Response, error) {
+	wrapper := customLineWrapper{}
+	res, err := s.client.post(path, payload, &wrapper)
+	if err != nil {
+		return CustomLine{}, res, err
+	}
+	s.client.Domains.InvalidateLineCatalog(domainID)
+
+	if wrapper.Line.ID != "" {
+		line.ID = wrapper.Line.ID
+	}
+	return line, res, nil
+}
+
+// Delete a custom line of a domain.
+func (s *CustomLinesService) Delete(domainID string, lineID string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("id", lineID)
+
+	res, err := s.client.post(customLineAction("Remove"), payload, nil)
+	if err != nil {
+		return res, err
+	}
+	s.client.Domains.InvalidateLineCatalog(domainID)
+	return res, nil
+}
+
+// List the line groups of a domain.
+func (s *LineGroupsService) List(domainID string) ([]LineGroup, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+
+	wrapper := lineGroupsWrapper{}
+	res, err := s.client.post(lineGroupAction("List"), payload, &wrapper)
+	if err != nil {
+		return nil, res, err
+	}
+
+	groups := make([]LineGroup, 0, len(wrapper.Groups))
+	for _, g := range wrapper.Groups {
+		groups = append(groups, g.lineGroup())
+	}
+	return groups, res, nil
+}
+
+// Create a line group for a domain.
+func (s *LineGroupsService) Create(domainID string, group LineGroup) (LineGroup, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("name", group.Name)
+	payload.Set("lines", strings.Join(group.Lines, ","))
+
+	return s.save(lineGroupAction("Create"), domainID, group, payload)
+}
+
+// Update a line group of a domain.
+func (s *LineGroupsService) Update(domainID string, group LineGroup) (LineGroup, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("line_group_id", group.ID)
+	payload.Set("name", group.Name)
+	payload.Set("lines", strings.Join(group.Lines, ","))
+
+	return s.save(lineGroupAction("Modify"), domainID, group, payload)
+}
+
+func (s *LineGroupsService) save(path, domainID string, group LineGroup, payload url.Values) (LineGroup, *Response, error) {
+	wrapper := lineGroupWrapper{}
+	res, err := s.client.post(path, payload, &wrapper)
+	if err != nil {
+		return LineGroup{}, res, err
+	}
+	s.client.Domains.InvalidateLineCatalog(domainID)
+
+	if wrapper.Group.ID != "" {
+		group.ID = wrapper.Group.ID
+	}
+	return group, res, nil
+}
+
+// Delete a line group of a domain.
+func (s *LineGroupsService) Delete(domainID string, groupID string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("line_group_id", groupID)
+
+	res, err := s.client.post(lineGroupAction("Remove"), payload, nil)
+	if err != nil {
+		return res, err
+	}
+	s.client.Domains.InvalidateLineCatalog(domainID)
+	return res, nil
+}
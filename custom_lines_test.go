@@ -0,0 +1,106 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestParseArea(t *testing.T) {
+	ranges, err := ParseArea("10.0.0.0/8, 192.168.1.1, 192.168.2.0-192.168.2.129")
+	if err != nil {
+		t.Fatalf("ParseArea returned error: %v", err)
+	}
+
+	want := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.1/32"),
+		netip.MustParsePrefix("192.168.2.0/25"),
+		netip.MustParsePrefix("192.168.2.128/31"),
+	}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("ParseArea returned %v, want %v", ranges, want)
+	}
+	testString(t, "FormatArea", FormatArea(ranges), "10.0.0.0/8,192.168.1.1/32,192.168.2.0/25,192.168.2.128/31")
+
+	if _, err := ParseArea("10.0.0.9-10.0.0.1"); err == nil {
+		t.Errorf("ParseArea accepted a reversed range")
+	}
+}
+
+func TestCustomLinesService_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Custom.Line.List", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprint(w, `{"status": {"code":"1"},"lines":[{"id":12,"name":"办公网","area":"10.0.0.0/8"}]}`)
+	})
+
+	lines, _, err := client.CustomLines.List("1")
+	if err != nil {
+		t.Fatalf("CustomLines.List returned error: %v", err)
+	}
+
+	want := []CustomLine{{ID: "12", Name: "办公网", Ranges: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("CustomLines.List returned %+v, want %+v", lines, want)
+	}
+}
+
+func TestCustomLinesService_Create_invalidatesCatalog(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.Info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"domain": {"id":1, "grade":"DP_Enterprise"}}`)
+	})
+	lines := `"默认"`
+	mux.HandleFunc("/Record.Line", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"status": {"code":"1"},"line_ids": {"默认": 0, "办公网": "1024"},"lines": [%s]}`, lines)
+	})
+	mux.HandleFunc("/Custom.Line.Create", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "domain_id": "1", "name": "办公网", "area": "10.0.0.0/8"})
+		lines = `"默认", "办公网"`
+		fmt.Fprint(w, `{"status": {"code":"1"},"line":{"id":"1024"}}`)
+	})
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"record":{"id":"1"}}`)
+	})
+
+	client.ValidateLines = true
+	if _, _, err := client.Domains.CreateRecord("1", Record{Name: "www", Line: "办公网"}); err == nil {
+		t.Fatalf("Domains.CreateRecord accepted a line before it was created")
+	}
+
+	line, _, err := client.CustomLines.Create("1", CustomLine{Name: "办公网", Ranges: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}})
+	if err != nil {
+		t.Fatalf("CustomLines.Create returned error: %v", err)
+	}
+	testString(t, "CustomLines.Create ID", line.ID, "1024")
+
+	if _, _, err := client.Domains.CreateRecord("1", Record{Name: "www", Line: "办公网"}); err != nil {
+		t.Errorf("Domains.CreateRecord returned error: %v", err)
+	}
+}
+
+func TestLineGroupsService_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Line.Group.List", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"line_groups":[{"id":3,"name":"南方","lines":"广东,广西"}]}`)
+	})
+
+	groups, _, err := client.LineGroups.List("1")
+	if err != nil {
+		t.Fatalf("LineGroups.List returned error: %v", err)
+	}
+
+	want := []LineGroup{{ID: "3", Name: "南方", Lines: []string{"广东", "广西"}}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("LineGroups.List returned %+v, want %+v", groups, want)
+	}
+}
@@ -0,0 +1,154 @@
+package dnspod
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+)
+
+// NSResolver looks up the nameservers a domain is delegated to.
+// *net.Resolver implements it; tests and offline tools can provide their own.
+type NSResolver interface {
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+}
+
+// gradeNameservers are the nameservers dnspod serves domains from, by grade.
+var gradeNameservers = map[string][]string{
+	"D_Free":    {"f1g1ns1.dnspod.net", "f1g1ns2.dnspod.net"},
+	"DP_Free":   {"f1g1ns1.dnspod.net", "f1g1ns2.dnspod.net"},
+	"D_Plus":    {"ns1.dnsv2.com", "ns2.dnsv2.com"},
+	"DP_Plus":   {"ns1.dnsv2.com", "ns2.dnsv2.com"},
+	"D_Extra":   {"ns1.dnsv3.com", "ns2.dnsv3.com"},
+	"DP_Extra":  {"ns1.dnsv3.com", "ns2.dnsv3.com"},
+	"D_Expert":  {"ns1.dnsv4.com", "ns2.dnsv4.com"},
+	"DP_Expert": {"ns1.dnsv4.com", "ns2.dnsv4.com"},
+	"D_Ultra":   {"ns1.dnsv5.com", "ns2.dnsv5.com"},
+	"DP_Ultra":  {"ns1.dnsv5.com", "ns2.dnsv5.com"},
+}
+
+// GradeNameservers returns the nameservers dnspod serves the domains of a grade from,
+// defaulting to the ones of the free grade.
+func GradeNameservers(grade string) []string {
+	if ns, ok := gradeNameservers[grade]; ok {
+		return append([]string(nil), ns...)
+	}
+	return append([]string(nil), gradeNameservers["DP_Free"]...)
+}
+
+// Delegation reports the nameservers a domain is delegated to at its registrar.
+type Delegation struct {
+	Domain      string   `json:"domain"`
+	Grade       string   `json:"grade"`
+	Nameservers []string `json:"nameservers"` // as resolved
+	Expected    []string `json:"expected"`    // the dnspod nameservers of the grade
+	Missing     []string `json:"missing,omitempty"`
+	Extra       []string `json:"extra,omitempty"`
+}
+
+// Delegated reports whether the domain is delegated to exactly the dnspod nameservers of its grade.
+func (d Delegation) Delegated() bool {
+	return len(d.Nameservers) > 0 && len(d.Missing) == 0 && len(d.Extra) == 0
+}
+
+// ServedByDNSPod reports whether the domain is delegated to dnspod at all,
+// possibly to the nameservers of another grade.
+func (d Delegation) ServedByDNSPod() bool {
+	if len(d.Nameservers) == 0 {
+		return false
+	}
+	for _, ns := range d.Nameservers {
+		if !dnspodNameserver(ns) {
+			return false
+		}
+	}
+	return true
+}
+
+func dnspodNameserver(ns string) bool {
+	for _, servers := range gradeNameservers {
+		for _, s := range servers {
+			if s == ns {
+				return true
+			}
+		}
+	}
+	return strings.HasSuffix(ns, ".dnspod.net")
+}
+
+func normalizeNameserver(ns string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(ns), "."))
+}
+
+// CheckDelegation resolves the nameservers of a domain and compares them with the
+// dnspod nameservers of its grade, to tell why a domain stays in an error state
+// after Create. A nil resolver uses net.DefaultResolver.
+func (s *DomainsService) CheckDelegation(ctx context.Context, domainID string, resolver NSResolver) (Delegation, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	returnedDomain := domainWrapper{}
+	if _, err := s.client.DoContext(ctx, "POST", domainAction("Info"), payload, &returnedDomain); err != nil {
+		return Delegation{}, err
+	}
+	return DomainDelegation(ctx, returnedDomain.Domain, resolver)
+}
+
+// DomainDelegation is CheckDelegation for a domain already fetched.
+func DomainDelegation(ctx context.Context, domain Domain, resolver NSResolver) (Delegation, error) {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	d := Delegation{Domain: domain.Name, Grade: domain.Grade, Expected: GradeNameservers(domain.Grade)}
+	if len(domain.DNSPodNS) > 0 {
+		d.Expected = d.Expected[:0]
+		for _, ns := range domain.DNSPodNS {
+			d.Expected = append(d.Expected, normalizeNameserver(ns))
+		}
+	}
+	sort.Strings(d.Expected)
+
+	records, err := resolver.LookupNS(ctx, domain.Name)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		return d, err
+	}
+	for _, r := range records {
+		d.Nameservers = append(d.Nameservers, normalizeNameserver(r.Host))
+	}
+	sort.Strings(d.Nameservers)
+
+	found := map[string]bool{}
+	for _, ns := range d.Nameservers {
+		found[ns] = true
+	}
+	expected := map[string]bool{}
+	for _, ns := range d.Expected {
+		expected[ns] = true
+		if !found[ns] {
+			d.Missing = append(d.Missing, ns)
+		}
+	}
+	for _, ns := range d.Nameservers {
+		if !expected[ns] {
+			d.Extra = append(d.Extra, ns)
+		}
+	}
+	return d, nil
+}
+
+// Transfer moves a domain to another dnspod account, identified by its email.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/domains.html#domain-transfer
+func (s *DomainsService) Transfer(domainID, email string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("email", email)
+
+	res, err := s.client.post(domainAction("Transfer"), payload, nil)
+	if err == nil {
+		s.InvalidateLineCatalog(domainID)
+	}
+	return res, err
+}
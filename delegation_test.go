@@ -0,0 +1,90 @@
+package dnspod
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// fakeResolver serves NS records from a map, reporting unknown names as not found.
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupNS(ctx context.Context, name string) ([]*net.NS, error) {
+	hosts, ok := f[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	var records []*net.NS
+	for _, h := range hosts {
+		records = append(records, &net.NS{Host: h})
+	}
+	return records, nil
+}
+
+func TestDomainsService_CheckDelegation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.Info", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "domain_id": "1"})
+		fmt.Fprint(w, `{"status": {"code":"1"},"domain": {"id":1, "name":"example.com", "grade":"DP_Plus"}}`)
+	})
+
+	resolver := fakeResolver{"example.com": {"NS1.DNSV2.COM.", "f1g1ns1.dnspod.net."}}
+	d, err := client.Domains.CheckDelegation(context.Background(), "1", resolver)
+	if err != nil {
+		t.Fatalf("Domains.CheckDelegation returned error: %v", err)
+	}
+
+	want := Delegation{
+		Domain:      "example.com",
+		Grade:       "DP_Plus",
+		Nameservers: []string{"f1g1ns1.dnspod.net", "ns1.dnsv2.com"},
+		Expected:    []string{"ns1.dnsv2.com", "ns2.dnsv2.com"},
+		Missing:     []string{"ns2.dnsv2.com"},
+		Extra:       []string{"f1g1ns1.dnspod.net"},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("Domains.CheckDelegation returned %+v, want %+v", d, want)
+	}
+	if d.Delegated() || !d.ServedByDNSPod() {
+		t.Errorf("Delegated() = %v, ServedByDNSPod() = %v, want false and true", d.Delegated(), d.ServedByDNSPod())
+	}
+}
+
+func TestDomainDelegation(t *testing.T) {
+	resolver := fakeResolver{"example.com": {"f1g1ns2.dnspod.net.", "f1g1ns1.dnspod.net."}}
+
+	d, err := DomainDelegation(context.Background(), Domain{Name: "example.com", Grade: "DP_Free"}, resolver)
+	if err != nil || !d.Delegated() {
+		t.Errorf("DomainDelegation returned %+v, %v, want a delegated domain", d, err)
+	}
+
+	d, err = DomainDelegation(context.Background(), Domain{Name: "missing.com", Grade: "DP_Free"}, resolver)
+	if err != nil || d.Delegated() || d.ServedByDNSPod() || len(d.Missing) != 2 {
+		t.Errorf("DomainDelegation of an unregistered domain returned %+v, %v", d, err)
+	}
+
+	d, _ = DomainDelegation(context.Background(), Domain{Name: "example.com", DNSPodNS: []string{"f1g1ns1.dnspod.net."}}, resolver)
+	if !reflect.DeepEqual(d.Extra, []string{"f1g1ns2.dnspod.net"}) {
+		t.Errorf("DomainDelegation ignored the dnspod_ns of the domain: %+v", d)
+	}
+}
+
+func TestDomainsService_Transfer(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.Transfer", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testFormValues(t, r, values{"login_token": "dnspod login token", "domain_id": "1", "email": "new-owner@example.com"})
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+
+	if _, err := client.Domains.Transfer("1", "new-owner@example.com"); err != nil {
+		t.Errorf("Domains.Transfer returned error: %v", err)
+	}
+}
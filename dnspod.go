@@ -4,7 +4,8 @@
 package dnspod
 
 import (
-	// "bytes"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -25,9 +26,75 @@ func init() {
 	jsoniter.RegisterFieldDecoderFunc("dnspod.Domain", "ID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 		*((*string)(ptr)) = iter.ReadAny().ToString()
 	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.DomainGroup", "ID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.AgentClient", "UserID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.agentInfo", "Total", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.monitor", "ID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.monitor", "DomainID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.monitor", "RecordID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.monitor", "StatusCode", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.monitor", "Port", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*int)(ptr)) = iter.ReadAny().ToInt()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.monitor", "Interval", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*int)(ptr)) = iter.ReadAny().ToInt()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.MonitorDown", "MonitorID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.monitorDownsInfo", "Total", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*int)(ptr)) = iter.ReadAny().ToInt()
+	})
 	jsoniter.RegisterFieldDecoderFunc("dnspod.Record", "ID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 		*((*string)(ptr)) = iter.ReadAny().ToString()
 	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.Record", "Weight", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.Record", "URLRedirectCode", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.User", "Balance", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*float64)(ptr)) = iter.ReadAny().ToFloat64()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.User", "SmsBalance", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*int)(ptr)) = iter.ReadAny().ToInt()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.customLine", "ID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.lineGroup", "ID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.Snapshot", "ID", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.Snapshot", "RecordCount", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.analyticsPoint", "Time", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*string)(ptr)) = iter.ReadAny().ToString()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.analyticsPoint", "Count", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*int)(ptr)) = iter.ReadAny().ToInt()
+	})
+	jsoniter.RegisterFieldDecoderFunc("dnspod.analyticsInfo", "Total", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		*((*int)(ptr)) = iter.ReadAny().ToInt()
+	})
 	jsoniter.RegisterFieldDecoderFunc("dnspod.DomainInfo", "ShareTotal", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 		*((*int)(ptr)) = iter.ReadAny().ToInt()
 	})
@@ -86,6 +153,20 @@ type Status struct {
 	CreatedAt string `json:"created_at,omitempty"`
 }
 
+// decodeStatus extracts the dnspod status from a raw response body.
+func decodeStatus(body []byte) (Status, bool) {
+	if len(body) == 0 {
+		return Status{}, false
+	}
+	wrapper := struct {
+		Status Status `json:"status"`
+	}{}
+	if err := json.Unmarshal(body, &wrapper); err != nil || wrapper.Status.Code == "" {
+		return Status{}, false
+	}
+	return wrapper.Status, true
+}
+
 type Client struct {
 	// HTTP client used to communicate with the API.
 	HttpClient *http.Client
@@ -101,18 +182,56 @@ type Client struct {
 	// User agent used when communicating with the dnspod API.
 	UserAgent string
 
+	// ValidateLines checks the Line and LineID of records against the LineCatalog
+	// of their domain before creating or updating them.
+	ValidateLines bool
+
+	// Middlewares wrapping every API call, outermost first. See Use.
+	Middlewares []Middleware
+
+	// Tracer and Meter, if set, instrument every API call made by the client.
+	Tracer Tracer
+	Meter  Meter
+
+	// Logger, if set, receives a record for every API call made by the client.
+	Logger Logger
+
+	// Hook, if set, is called with the details of every API call made by the client.
+	Hook func(LogEntry)
+
+	// LogBodies includes the redacted request payload and the raw response body
+	// in the records passed to Logger and Hook.
+	LogBodies bool
+
 	// Services used for talking to different parts of the dnspod API.
-	Domains *DomainsService
+	Domains     *DomainsService
+	CustomLines *CustomLinesService
+	LineGroups  *LineGroupsService
+	Monitors    *MonitorsService
+	Users       *UsersService
+	Snapshots   *SnapshotsService
+	Agent       *AgentService
 }
 
 // NewClient returns a new dnspod API client.
 func NewClient(CommonParams CommonParams) *Client {
 	c := &Client{HttpClient: &http.Client{}, CommonParams: CommonParams, BaseURL: baseURL, UserAgent: userAgent}
-	c.Domains = &DomainsService{client: c}
+	c.initServices()
 	return c
 
 }
 
+// initServices sets up the services of the client, shared by NewClient and Client.ForUser.
+func (c *Client) initServices() {
+	c.Domains = &DomainsService{client: c}
+	c.CustomLines = &CustomLinesService{client: c}
+	c.LineGroups = &LineGroupsService{client: c}
+	c.Monitors = &MonitorsService{client: c}
+	c.Users = &UsersService{client: c}
+	c.Snapshots = &SnapshotsService{client: c}
+	c.Agent = &AgentService{client: c}
+}
+
 // NewRequest creates an API request.
 // The path is expected to be a relative path and will be resolved
 // according to the BaseURL of the Client. Paths should always be specified without a preceding slash.
@@ -139,6 +258,10 @@ func (c *Client) post(path string, payload url.Values, v interface{}) (*Response
 	return c.Do("POST", path, payload, v)
 }
 
+func (c *Client) postContext(ctx context.Context, path string, payload url.Values, v interface{}) (*Response, error) {
+	return c.DoContext(ctx, "POST", path, payload, v)
+}
+
 func (c *Client) put(path string, payload url.Values, v interface{}) (*Response, error) {
 	return c.Do("PUT", path, payload, v)
 }
@@ -153,26 +276,26 @@ func (c *Client) delete(path string, payload url.Values) (*Response, error) {
 // If v implements the io.Writer interface, the raw response body will be written to v,
 // without attempting to decode it.
 func (c *Client) Do(method, path string, payload url.Values, v interface{}) (*Response, error) {
-	req, err := c.NewRequest(method, path, payload)
-	if err != nil {
-		return nil, err
-	}
+	return c.DoContext(context.Background(), method, path, payload, v)
+}
 
-	res, err := c.HttpClient.Do(req)
-	if err != nil {
-		return nil, err
+// DoContext is like Do, the request being bound to ctx.
+func (c *Client) DoContext(ctx context.Context, method, path string, payload url.Values, v interface{}) (*Response, error) {
+	req := &Request{Context: ctx, Method: method, Action: path, Payload: payload}
+	result, err := c.handler().Handle(req)
+
+	var response *Response
+	if result != nil {
+		response = result.Response
 	}
-	defer res.Body.Close()
-	response := &Response{Response: res}
-	err = CheckResponse(res)
 	if err != nil {
 		return response, err
 	}
 	if v != nil {
 		if w, ok := v.(io.Writer); ok {
-			io.Copy(w, res.Body)
+			_, err = w.Write(result.Body)
 		} else {
-			d := json.NewDecoder(res.Body)
+			d := json.NewDecoder(bytes.NewReader(result.Body))
 			err = d.Decode(v)
 		}
 	}
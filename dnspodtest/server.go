@@ -0,0 +1,472 @@
+// Package dnspodtest provides an in-memory dnspod API server for tests.
+//
+// The server implements the domain and record actions used by the adapters of
+// this module, with the response formats of the dnspod API:
+//
+//	srv := dnspodtest.NewServer()
+//	defer srv.Close()
+//	id := srv.AddDomain("example.com")
+//	client := srv.Client()
+//	record, _, err := client.Domains.CreateRecord(id, dnspod.Record{Name: "www", Type: "A", Line: "默认", Value: "1.2.3.4"})
+package dnspodtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decker502/dnspod-go"
+)
+
+// Token is the login token accepted by the server.
+const Token = "dnspodtest,token"
+
+// Status codes returned by the server.
+const (
+	CodeOK               = "1"
+	CodeLoginFailed      = "-1"
+	CodeUnknownAction    = "-404"
+	CodeMissingParam     = "2"
+	CodeInvalidDomainID  = "6"
+	CodeInvalidRecordID  = "8"
+	CodeNoRecords        = "10"
+	CodeDomainExists     = "7"
+	CodeRecordExists     = "104"
+	CodeInvalidSubdomain = "22"
+	CodeInvalidLine      = "26"
+)
+
+// lines are the lines served to the domains, all of the free grade, in dnspod order.
+var lines = []struct{ name, id string }{
+	{dnspod.DefaultLine, "0"},
+	{"国内", "7=0"},
+	{"国外", "3=0"},
+	{"电信", "10=0"},
+	{"联通", "10=1"},
+	{"教育网", "10=2"},
+	{"移动", "10=3"},
+	{"搜索引擎", "80=0"},
+	{"百度", "90=0"},
+}
+
+// resolveLine sets both the Line and LineID of a record from either, the default
+// line when both are empty. It reports whether the line is served.
+func resolveLine(r *dnspod.Record) bool {
+	if r.Line == "" && r.LineID == "" {
+		r.Line = dnspod.DefaultLine
+	}
+	for _, l := range lines {
+		if (r.Line != "" && r.Line == l.name) || (r.Line == "" && r.LineID == l.id) {
+			r.Line, r.LineID = l.name, l.id
+			return true
+		}
+	}
+	return false
+}
+
+// Server is a fake dnspod API backed by memory. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// PageLimit, when set, caps the length of the pages of domains and records listed,
+	// so that tests can exercise the pagination.
+	PageLimit int
+
+	mu      sync.Mutex
+	nextID  int
+	domains []*domain
+	calls   map[string]int
+}
+
+type domain struct {
+	dnspod.Domain
+	records []dnspod.Record
+}
+
+// NewServer starts a Server. Callers should Close it when done.
+func NewServer() *Server {
+	s := &Server{nextID: 1000, calls: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client of the server authenticated with Token.
+func (s *Server) Client() *dnspod.Client {
+	client := dnspod.NewClient(dnspod.CommonParams{LoginToken: Token})
+	client.BaseURL = s.URL + "/"
+	return client
+}
+
+// AddDomain adds a domain of the free grade along with its apex NS records and the
+// given records, returning its ID.
+func (s *Server) AddDomain(name string, records ...dnspod.Record) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.addDomain(name)
+	for _, r := range records {
+		s.addRecord(d, r)
+	}
+	return d.ID
+}
+
+// Records returns the records of a domain, by ID or name.
+func (s *Server) Records(domain string) []dnspod.Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.domain(url.Values{"domain_id": {domain}, "domain": {domain}})
+	if d == nil {
+		return nil
+	}
+	return append([]dnspod.Record(nil), d.records...)
+}
+
+// Calls returns the number of calls received for an action, e.g. "Record.Create".
+func (s *Server) Calls(action string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[action]
+}
+
+func (s *Server) id() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+func (s *Server) addDomain(name string) *domain {
+	d := &domain{Domain: dnspod.Domain{
+		ID:        s.id(),
+		Name:      name,
+		Grade:     "DP_Free",
+		GroupID:   "1",
+		Status:    "enable",
+		TTL:       "600",
+		CreatedOn: time.Now().Format("2006-01-02 15:04:05"),
+		DNSPodNS:  dnspod.GradeNameservers("DP_Free"),
+	}}
+	for _, ns := range d.DNSPodNS {
+		s.addRecord(d, dnspod.Record{Name: "@", Type: "NS", Value: ns + ".", TTL: "86400"})
+	}
+	s.domains = append(s.domains, d)
+	return d
+}
+
+func (s *Server) addRecord(d *domain, r dnspod.Record) dnspod.Record {
+	r.ID = s.id()
+	if r.Name == "" {
+		r.Name = "@"
+	}
+	resolveLine(&r)
+	if r.TTL == "" {
+		r.TTL = d.TTL
+	}
+	if r.MX == "" {
+		r.MX = "0"
+	}
+	if r.Status == "disable" || r.Status == "disabled" || r.Enabled == "0" {
+		r.Enabled = "0"
+	} else {
+		r.Enabled = "1"
+	}
+	r.Status = ""
+	r.UpdateOn = time.Now().Format("2006-01-02 15:04:05")
+	d.records = append(d.records, r)
+	return r
+}
+
+// domain returns the domain a payload refers to.
+func (s *Server) domain(params url.Values) *domain {
+	id, name := params.Get("domain_id"), strings.TrimSuffix(params.Get("domain"), ".")
+	for _, d := range s.domains {
+		if (id != "" && d.ID == id) || (name != "" && d.Name == name) {
+			return d
+		}
+	}
+	return nil
+}
+
+type response map[string]interface{}
+
+type apiError struct {
+	code, message string
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	action := strings.TrimPrefix(r.URL.Path, "/")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls[action]++
+	var (
+		body response
+		err  *apiError
+	)
+	if r.PostForm.Get("login_token") != Token {
+		err = &apiError{CodeLoginFailed, "Login fail, please check login info"}
+	} else {
+		body, err = s.handle(action, r.PostForm)
+	}
+	s.mu.Unlock()
+
+	status := map[string]string{"code": CodeOK, "message": "Action completed successful", "created_at": time.Now().Format("2006-01-02 15:04:05")}
+	if err != nil {
+		body = response{}
+		status["code"], status["message"] = err.code, err.message
+	}
+	if body == nil {
+		body = response{}
+	}
+	body["status"] = status
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func (s *Server) handle(action string, params url.Values) (response, *apiError) {
+	switch action {
+	case "Info.Version":
+		return nil, nil
+	case "User.Detail":
+		return response{"info": response{"user": response{"id": "1", "email": "dnspodtest@example.com", "status": "enabled"}}}, nil
+	case "Domain.List":
+		return s.listDomains(params), nil
+	case "Domain.Create":
+		return s.createDomain(params)
+	case "Domain.Info":
+		if d := s.domain(params); d != nil {
+			return response{"domain": d.Domain}, nil
+		}
+		return nil, &apiError{CodeInvalidDomainID, "Domain id invalid"}
+	case "Domain.Remove":
+		return s.removeDomain(params)
+	case "Domain.Grouplist":
+		return response{"groups": []response{{"group_id": 1, "group_name": "默认分组", "group_type": "system"}}}, nil
+	}
+
+	if !strings.HasPrefix(action, "Record.") {
+		return nil, &apiError{CodeUnknownAction, "Unknown action " + action}
+	}
+	d := s.domain(params)
+	if d == nil {
+		return nil, &apiError{CodeInvalidDomainID, "Domain id invalid"}
+	}
+	switch action {
+	case "Record.List":
+		return s.listRecords(d, params)
+	case "Record.Create":
+		return s.createRecord(d, params)
+	case "Record.Line":
+		return listLines(), nil
+	}
+
+	i := -1
+	for j, r := range d.records {
+		if r.ID == params.Get("record_id") {
+			i = j
+		}
+	}
+	if i < 0 {
+		return nil, &apiError{CodeInvalidRecordID, "Record id invalid"}
+	}
+	switch action {
+	case "Record.Info":
+		return response{"record": d.records[i]}, nil
+	case "Record.Modify":
+		return s.modifyRecord(d, i, params)
+	case "Record.Remove":
+		d.records = append(d.records[:i], d.records[i+1:]...)
+		return nil, nil
+	case "Record.Remark":
+		d.records[i].Remark = params.Get("remark")
+		return nil, nil
+	case "Record.Status":
+		d.records[i].Enabled = "1"
+		if params.Get("status") == "disable" {
+			d.records[i].Enabled = "0"
+		}
+		return response{"record": response{"id": d.records[i].ID, "name": d.records[i].Name, "status": params.Get("status")}}, nil
+	}
+	return nil, &apiError{CodeUnknownAction, "Unknown action " + action}
+}
+
+// page returns the bounds of the page selected by the offset and length parameters.
+func (s *Server) page(params url.Values, total int) (int, int) {
+	offset, _ := strconv.Atoi(params.Get("offset"))
+	length, err := strconv.Atoi(params.Get("length"))
+	if err != nil || length <= 0 {
+		length = total
+	}
+	if s.PageLimit > 0 && length > s.PageLimit {
+		length = s.PageLimit
+	}
+	if offset > total {
+		offset = total
+	}
+	end := offset + length
+	if end > total {
+		end = total
+	}
+	return offset, end
+}
+
+func (s *Server) listDomains(params url.Values) response {
+	var domains []dnspod.Domain
+	for _, d := range s.domains {
+		if keyword := params.Get("keyword"); keyword == "" || strings.Contains(d.Name, keyword) {
+			domains = append(domains, d.Domain)
+		}
+	}
+	start, end := s.page(params, len(domains))
+	info := response{"domain_total": len(domains), "all_total": len(domains), "mine_total": len(domains)}
+	return response{"info": info, "domains": append([]dnspod.Domain{}, domains[start:end]...)}
+}
+
+func (s *Server) createDomain(params url.Values) (response, *apiError) {
+	name := strings.TrimSuffix(params.Get("domain"), ".")
+	if name == "" {
+		return nil, &apiError{CodeMissingParam, "Domain is empty"}
+	}
+	if s.domain(url.Values{"domain": {name}}) != nil {
+		return nil, &apiError{CodeDomainExists, "Domain is exists"}
+	}
+	d := s.addDomain(name)
+	return response{"domain": response{"id": d.ID, "punycode": d.Name, "domain": d.Name}}, nil
+}
+
+func (s *Server) removeDomain(params url.Values) (response, *apiError) {
+	for i, d := range s.domains {
+		if d.ID == params.Get("domain_id") {
+			s.domains = append(s.domains[:i], s.domains[i+1:]...)
+			return nil, nil
+		}
+	}
+	return nil, &apiError{CodeInvalidDomainID, "Domain id invalid"}
+}
+
+func (s *Server) listRecords(d *domain, params url.Values) (response, *apiError) {
+	var records []dnspod.Record
+	for _, r := range d.records {
+		if sub := params.Get("sub_domain"); sub != "" && r.Name != sub {
+			continue
+		}
+		if t := params.Get("record_type"); t != "" && r.Type != t {
+			continue
+		}
+		if keyword := params.Get("keyword"); keyword != "" && !strings.Contains(r.Name, keyword) && !strings.Contains(r.Value, keyword) {
+			continue
+		}
+		records = append(records, r)
+	}
+	if len(records) == 0 {
+		return nil, &apiError{CodeNoRecords, "No records"}
+	}
+	subdomains := map[string]bool{}
+	for _, r := range records {
+		subdomains[r.Name] = true
+	}
+	start, end := s.page(params, len(records))
+	info := response{"sub_domains": strconv.Itoa(len(subdomains)), "record_total": strconv.Itoa(len(records))}
+	return response{"domain": response{"id": d.ID, "name": d.Name, "grade": d.Grade}, "info": info, "records": append([]dnspod.Record{}, records[start:end]...)}, nil
+}
+
+// listLines returns the lines served, as Record.Line does.
+func listLines() response {
+	names := make([]string, 0, len(lines))
+	ids := response{}
+	for _, l := range lines {
+		names = append(names, l.name)
+		ids[l.name] = l.id
+	}
+	return response{"lines": names, "line_ids": ids}
+}
+
+// recordParams reads the record attributes of a Record.Create or Record.Modify payload.
+func recordParams(params url.Values) (dnspod.Record, *apiError) {
+	r := dnspod.Record{
+		Name:   params.Get("sub_domain"),
+		Type:   strings.ToUpper(params.Get("record_type")),
+		Line:   params.Get("record_line"),
+		LineID: params.Get("record_line_id"),
+		Value:  params.Get("value"),
+		MX:     params.Get("mx"),
+		TTL:    params.Get("ttl"),
+		Status: params.Get("status"),
+		Weight: params.Get("weight"),
+	}
+	if r.Type == "" || r.Value == "" || (r.Line == "" && r.LineID == "") {
+		return r, &apiError{CodeMissingParam, "Missing record_type, record_line or value"}
+	}
+	if strings.HasPrefix(r.Name, ".") || strings.HasSuffix(r.Name, ".") || strings.Contains(r.Name, "..") {
+		return r, &apiError{CodeInvalidSubdomain, "Subdomain invalid"}
+	}
+	if !resolveLine(&r) {
+		return r, &apiError{CodeInvalidLine, "Record line invalid"}
+	}
+	if r.Type == "MX" && r.MX == "" {
+		r.MX = "10"
+	}
+	if r.Name == "" {
+		r.Name = "@"
+	}
+	return r, nil
+}
+
+// sameRecord reports whether two records would be duplicates for dnspod.
+func sameRecord(a, b dnspod.Record) bool {
+	return strings.EqualFold(a.Name, b.Name) && a.Type == b.Type && a.Value == b.Value &&
+		(a.Line == b.Line || (a.LineID != "" && a.LineID == b.LineID))
+}
+
+func (s *Server) createRecord(d *domain, params url.Values) (response, *apiError) {
+	r, err := recordParams(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range d.records {
+		if sameRecord(existing, r) {
+			return nil, &apiError{CodeRecordExists, "Record already exists"}
+		}
+	}
+	r = s.addRecord(d, r)
+	return response{"record": response{"id": r.ID, "name": r.Name, "status": "enabled"}}, nil
+}
+
+func (s *Server) modifyRecord(d *domain, i int, params url.Values) (response, *apiError) {
+	r, err := recordParams(params)
+	if err != nil {
+		return nil, err
+	}
+	for j, existing := range d.records {
+		if j != i && sameRecord(existing, r) {
+			return nil, &apiError{CodeRecordExists, "Record already exists"}
+		}
+	}
+
+	current := &d.records[i]
+	current.Name, current.Type, current.Value = r.Name, r.Type, r.Value
+	current.Line, current.LineID = r.Line, r.LineID
+	if r.TTL != "" {
+		current.TTL = r.TTL
+	}
+	current.MX = r.MX
+	if current.MX == "" {
+		current.MX = "0"
+	}
+	current.Weight = r.Weight
+	switch r.Status {
+	case "enable":
+		current.Enabled = "1"
+	case "disable":
+		current.Enabled = "0"
+	}
+	current.UpdateOn = time.Now().Format("2006-01-02 15:04:05")
+	return response{"record": response{"id": current.ID, "name": current.Name, "value": current.Value, "status": r.Status}}, nil
+}
@@ -0,0 +1,78 @@
+package dnspodtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/decker502/dnspod-go"
+)
+
+func TestServer_Records(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	id := srv.AddDomain("example.com")
+	client := srv.Client()
+
+	for _, value := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		if _, _, err := client.Domains.CreateRecord(id, dnspod.Record{Name: "www", Type: "A", Line: dnspod.DefaultLine, Value: value}); err != nil {
+			t.Fatalf("CreateRecord returned error: %v", err)
+		}
+	}
+
+	page, _, err := client.Domains.ListRecords(dnspod.RecordQuery{DomainID: id, CurrentPage: 2, PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListRecords returned error: %v", err)
+	}
+	if page.Total != 5 || len(page.List) != 2 || page.List[0].Value != "192.0.2.1" {
+		t.Errorf("ListRecords returned %+v", page)
+	}
+
+	_, _, err = client.Domains.CreateRecord(id, dnspod.Record{Name: "www", Type: "A", Line: dnspod.DefaultLine, Value: "192.0.2.1"})
+	var apiErr *dnspod.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status.Code != CodeRecordExists {
+		t.Errorf("CreateRecord of a duplicate returned %v, want code %s", err, CodeRecordExists)
+	}
+}
+
+func TestServer_LoginFailed(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	client := srv.Client()
+	client.CommonParams.LoginToken = "wrong"
+	_, err := client.Ping(context.Background())
+	var apiErr *dnspod.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status.Code != CodeLoginFailed {
+		t.Errorf("Ping with a wrong token returned %v, want code %s", err, CodeLoginFailed)
+	}
+}
+
+func TestServer_Lines(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	id := srv.AddDomain("example.com")
+	client := srv.Client()
+
+	if _, _, err := client.Domains.CreateRecord(id, dnspod.Record{Name: "www", Type: "A", Line: "电信", Value: "192.0.2.1"}); err != nil {
+		t.Fatalf("CreateRecord returned error: %v", err)
+	}
+	if _, _, err := client.Domains.CreateRecord(id, dnspod.Record{Name: "www", Type: "A", LineID: "10=1", Value: "192.0.2.2"}); err != nil {
+		t.Fatalf("CreateRecord returned error: %v", err)
+	}
+	var lines []string
+	for _, r := range srv.Records(id) {
+		if r.Name == "www" {
+			lines = append(lines, r.Line+" "+r.LineID)
+		}
+	}
+	if len(lines) != 2 || lines[0] != "电信 10=0" || lines[1] != "联通 10=1" {
+		t.Errorf("Records returned the lines %q", lines)
+	}
+
+	_, _, err := client.Domains.CreateRecord(id, dnspod.Record{Name: "www", Type: "A", Line: "办公网", Value: "192.0.2.3"})
+	var apiErr *dnspod.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status.Code != CodeInvalidLine {
+		t.Errorf("CreateRecord on an unknown line returned %v, want code %s", err, CodeInvalidLine)
+	}
+}
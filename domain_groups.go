@@ -0,0 +1,31 @@
+package dnspod
+
+// DomainGroup is a group of domains of the account.
+type DomainGroup struct {
+	ID   string `json:"group_id,omitempty"`
+	Name string `json:"group_name,omitempty"`
+	Type string `json:"group_type,omitempty"` // "system" or "user"
+}
+
+type domainGroupsWrapper struct {
+	Status Status        `json:"status"`
+	Groups []DomainGroup `json:"groups"`
+}
+
+func init() {
+	registerReadOnly(domainAction("Grouplist"))
+}
+
+// ListGroups lists the domain groups of the account.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/domains.html#domain-grouplist
+func (s *DomainsService) ListGroups() ([]DomainGroup, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+
+	wrapper := domainGroupsWrapper{}
+	res, err := s.client.post(domainAction("Grouplist"), payload, &wrapper)
+	if err != nil {
+		return nil, res, err
+	}
+	return wrapper.Groups, res, nil
+}
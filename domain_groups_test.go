@@ -0,0 +1,29 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDomainsService_ListGroups(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.Grouplist", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testFormValues(t, r, values{"login_token": "dnspod login token"})
+		fmt.Fprint(w, `{"status": {"code":"1"},"groups":[{"group_id":1,"group_name":"默认分组","group_type":"system"},{"group_id":"9","group_name":"production","group_type":"user"}]}`)
+	})
+
+	groups, _, err := client.Domains.ListGroups()
+	if err != nil {
+		t.Fatalf("Domains.ListGroups returned error: %v", err)
+	}
+
+	want := []DomainGroup{{ID: "1", Name: "默认分组", Type: "system"}, {ID: "9", Name: "production", Type: "user"}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("Domains.ListGroups returned %+v, want %+v", groups, want)
+	}
+}
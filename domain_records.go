@@ -1,8 +1,12 @@
 package dnspod
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
 )
 
 type Record struct {
@@ -24,6 +28,78 @@ type Record struct {
 	RecordType string `json:"record_type,omitempty"`
 	RecordLine string `json:"record_line,omitempty"`
 	RecordLineID string `json:"record_line_id,omitempty"`
+
+	// Weight balances the records sharing a name, type and line, from 0 to 100.
+	Weight string `json:"weight,omitempty"`
+
+	// URL forwarding options of 显性URL and 隐性URL records.
+	URLRedirectCode string `json:"url_redirect_code,omitempty"` // 301 or 302, 显性URL only
+	URLKeepPath     string `json:"url_keep_path,omitempty"`     // "yes" to append the requested path to Value
+	URLTitle        string `json:"url_title,omitempty"`         // page title, 隐性URL only
+	URLKeywords     string `json:"url_keywords,omitempty"`      // page keywords, 隐性URL only
+	URLDescription  string `json:"url_description,omitempty"`   // page description, 隐性URL only
+}
+
+// Record types of URL forwarding records.
+const (
+	RecordTypeExplicitURL = "显性URL"
+	RecordTypeImplicitURL = "隐性URL"
+)
+
+// addRecordAttributes sets the payload parameters of the non-empty record attributes.
+func addRecordAttributes(payload url.Values, r Record) {
+	params := []struct{ key, value string }{
+		{"sub_domain", r.Name},
+		{"record_type", r.Type},
+		{"record_line", r.Line},
+		{"record_line_id", r.LineID},
+		{"value", r.Value},
+		{"mx", r.MX},
+		{"ttl", r.TTL},
+		{"status", r.Status},
+		{"weight", r.Weight},
+		{"url_redirect_code", r.URLRedirectCode},
+		{"url_keep_path", r.URLKeepPath},
+		{"url_title", r.URLTitle},
+		{"url_keywords", r.URLKeywords},
+		{"url_description", r.URLDescription},
+	}
+	for _, p := range params {
+		if p.value != "" {
+			payload.Add(p.key, p.value)
+		}
+	}
+}
+
+// validateRecordOptions checks the weight and URL forwarding options of a record.
+func validateRecordOptions(r Record) error {
+	if r.Weight != "" {
+		w, err := strconv.Atoi(r.Weight)
+		if err != nil || w < 0 || w > 100 {
+			return fmt.Errorf("invalid record weight %q: must be between 0 and 100", r.Weight)
+		}
+	}
+
+	isURL := r.Type == RecordTypeExplicitURL || r.Type == RecordTypeImplicitURL
+	if r.URLRedirectCode != "" || r.URLKeepPath != "" {
+		if r.Type != "" && !isURL {
+			return fmt.Errorf("URL forwarding options require a %s or %s record, got %s", RecordTypeExplicitURL, RecordTypeImplicitURL, r.Type)
+		}
+	}
+	if r.URLRedirectCode != "" {
+		if r.Type == RecordTypeImplicitURL {
+			return fmt.Errorf("redirect code is not supported by %s records", RecordTypeImplicitURL)
+		}
+		if r.URLRedirectCode != "301" && r.URLRedirectCode != "302" {
+			return fmt.Errorf("invalid redirect code %q: must be 301 or 302", r.URLRedirectCode)
+		}
+	}
+	if r.URLTitle != "" || r.URLKeywords != "" || r.URLDescription != "" {
+		if r.Type != "" && r.Type != RecordTypeImplicitURL {
+			return fmt.Errorf("page options require a %s record, got %s", RecordTypeImplicitURL, r.Type)
+		}
+	}
+	return nil
 }
 
 type RecordsInfo struct {
@@ -88,6 +164,10 @@ func recordAction(action string) string {
 // dnspod API docs: https://www.dnspod.cn/docs/records.html#record-list
 
 func (s *DomainsService) ListRecords(query RecordQuery) (PaginationRecordList, *Response, error) {
+	return s.listRecords(context.Background(), query)
+}
+
+func (s *DomainsService) listRecords(ctx context.Context, query RecordQuery) (PaginationRecordList, *Response, error) {
 	path := recordAction("List")
 
 	payload := newPayLoad(s.client.CommonParams)
@@ -112,15 +192,11 @@ func (s *DomainsService) ListRecords(query RecordQuery) (PaginationRecordList, *
 
 	wrappedRecords := recordsWrapper{}
 
-	res, err := s.client.post(path, payload, &wrappedRecords)
+	res, err := s.client.postContext(ctx, path, payload, &wrappedRecords)
 	if err != nil {
 		return PaginationRecordList{}, res, err
 	}
 
-	if wrappedRecords.Status.Code != "1" {
-		return PaginationRecordList{}, nil, fmt.Errorf("Could not get domains: %s", wrappedRecords.Status.Message)
-	}
-
 	return PaginationRecordList{
 		CurrentPage: query.CurrentPage,
 		PageSize: query.PageSize,
@@ -129,60 +205,69 @@ func (s *DomainsService) ListRecords(query RecordQuery) (PaginationRecordList, *
 	}, res, nil
 }
 
+// IsNoRecords reports whether err is dnspod answering Record.List with status code 10,
+// as it does when no record matches, e.g. a sub domain without records.
+func IsNoRecords(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Action == recordAction("List") && apiErr.Status.Code == "10"
+}
+
+// IsInvalidRecordID reports whether err is dnspod answering a record action with status
+// code 8, as it does for a record ID missing from the domain, e.g. a record already deleted.
+func IsInvalidRecordID(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && strings.HasPrefix(apiErr.Action, "Record.") && apiErr.Status.Code == "8"
+}
+
 // CreateRecord creates a domain record.
 //
 // dnspod API docs: https://www.dnspod.cn/docs/records.html#record-create
 func (s *DomainsService) CreateRecord(domain string, recordAttributes Record) (Record, *Response, error) {
-	path := recordAction("Create")
-
-	payload := newPayLoad(s.client.CommonParams)
-
-	payload.Add("domain_id", domain)
-
-	if recordAttributes.Name != "" {
-		payload.Add("sub_domain", recordAttributes.Name)
-	}
-
-	if recordAttributes.Type != "" {
-		payload.Add("record_type", recordAttributes.Type)
-	}
+	return s.createRecord(context.Background(), domain, recordAttributes)
+}
 
-	if recordAttributes.Line != "" {
-		payload.Add("record_line", recordAttributes.Line)
-	}
+func (s *DomainsService) createRecord(ctx context.Context, domain string, recordAttributes Record) (Record, *Response, error) {
+	path := recordAction("Create")
 
-	if recordAttributes.LineID != "" {
-		payload.Add("record_line_id", recordAttributes.LineID)
+	if err := validateRecordOptions(recordAttributes); err != nil {
+		return Record{}, nil, err
 	}
-
-	if recordAttributes.Value != "" {
-		payload.Add("value", recordAttributes.Value)
+	recordAttributes, err := s.validateRecordLine(ctx, domain, recordAttributes)
+	if err != nil {
+		return Record{}, nil, err
 	}
 
-	if recordAttributes.MX != "" {
-		payload.Add("mx", recordAttributes.MX)
-	}
+	payload := newPayLoad(s.client.CommonParams)
 
-	if recordAttributes.TTL != "" {
-		payload.Add("ttl", recordAttributes.TTL)
-	}
+	payload.Add("domain_id", domain)
 
-	if recordAttributes.Status != "" {
-		payload.Add("status", recordAttributes.Status)
-	}
+	addRecordAttributes(payload, recordAttributes)
 
 	returnedRecord := recordWrapper{}
 
-	res, err := s.client.post(path, payload, &returnedRecord)
+	res, err := s.client.postContext(ctx, path, payload, &returnedRecord)
 	if err != nil {
 		return Record{}, res, err
 	}
 
-	if returnedRecord.Status.Code != "1" {
-		return returnedRecord.Record, nil, fmt.Errorf("Could not get domains: %s", returnedRecord.Status.Message)
-	}
+	return s.applyRecordRemark(ctx, domain, returnedRecord.Record.ID, returnedRecord.Record, recordAttributes.Remark, res)
+}
 
-	return returnedRecord.Record, res, nil
+// applyRecordRemark sets the remark of a record just created or updated, dnspod
+// setting remarks through Record.Remark alone. An empty remark is left unchanged.
+// When setting the remark fails, the record is returned along with the error,
+// its ID set, since the record itself was created or updated.
+func (s *DomainsService) applyRecordRemark(ctx context.Context, domain, recordID string, record Record, remark string, res *Response) (Record, *Response, error) {
+	if remark == "" {
+		return record, res, nil
+	}
+	res, err := s.setRecordRemark(ctx, domain, recordID, remark)
+	if err != nil {
+		record.ID = recordID
+		return record, res, err
+	}
+	record.Remark = remark
+	return record, res, nil
 }
 
 // GetRecord fetches the domain record.
@@ -203,9 +288,6 @@ func (s *DomainsService) GetRecord(domain string, recordID string) (Record, *Res
 		return Record{}, res, err
 	}
 
-	if returnedRecord.Status.Code != "1" {
-		return returnedRecord.Record, nil, fmt.Errorf("Could not get domains: %s", returnedRecord.Status.Message)
-	}
 	record := returnedRecord.Record
 	if record.Type == "" && record.RecordType != "" {
 		record.Type = record.RecordType
@@ -226,64 +308,46 @@ func (s *DomainsService) GetRecord(domain string, recordID string) (Record, *Res
 //
 // dnspod API docs: https://www.dnspod.cn/docs/records.html#record-modify
 func (s *DomainsService) UpdateRecord(domain string, recordID string, recordAttributes Record) (Record, *Response, error) {
-	path := recordAction("Modify")
-
-	payload := newPayLoad(s.client.CommonParams)
-
-	payload.Add("domain_id", domain)
-
-	payload.Add("record_id", recordID)
-
-	if recordAttributes.Name != "" {
-		payload.Add("sub_domain", recordAttributes.Name)
-	}
+	return s.updateRecord(context.Background(), domain, recordID, recordAttributes)
+}
 
-	if recordAttributes.Type != "" {
-		payload.Add("record_type", recordAttributes.Type)
-	}
+func (s *DomainsService) updateRecord(ctx context.Context, domain string, recordID string, recordAttributes Record) (Record, *Response, error) {
+	path := recordAction("Modify")
 
-	if recordAttributes.Line != "" {
-		payload.Add("record_line", recordAttributes.Line)
+	if err := validateRecordOptions(recordAttributes); err != nil {
+		return Record{}, nil, err
 	}
-
-	if recordAttributes.LineID != "" {
-		payload.Add("record_line_id", recordAttributes.LineID)
+	recordAttributes, err := s.validateRecordLine(ctx, domain, recordAttributes)
+	if err != nil {
+		return Record{}, nil, err
 	}
 
-	if recordAttributes.Value != "" {
-		payload.Add("value", recordAttributes.Value)
-	}
+	payload := newPayLoad(s.client.CommonParams)
 
-	if recordAttributes.MX != "" {
-		payload.Add("mx", recordAttributes.MX)
-	}
+	payload.Add("domain_id", domain)
 
-	if recordAttributes.TTL != "" {
-		payload.Add("ttl", recordAttributes.TTL)
-	}
+	payload.Add("record_id", recordID)
 
-	if recordAttributes.Status != "" {
-		payload.Add("status", recordAttributes.Status)
-	}
+	addRecordAttributes(payload, recordAttributes)
 
 	returnedRecord := recordWrapper{}
 
-	res, err := s.client.post(path, payload, &returnedRecord)
+	res, err := s.client.postContext(ctx, path, payload, &returnedRecord)
 	if err != nil {
 		return Record{}, res, err
 	}
 
-	if returnedRecord.Status.Code != "1" {
-		return returnedRecord.Record, nil, fmt.Errorf("Could not get domains: %s", returnedRecord.Status.Message)
-	}
-
-	return returnedRecord.Record, res, nil
+	return s.applyRecordRemark(ctx, domain, recordID, returnedRecord.Record, recordAttributes.Remark, res)
 }
 
 // DeleteRecord deletes a domain record.
 //
 // dnspod API docs: https://www.dnspod.cn/docs/records.html#record-remove
 func (s *DomainsService) DeleteRecord(domain string, recordID string) (*Response, error) {
+	return s.deleteRecord(context.Background(), domain, recordID)
+}
+
+func (s *DomainsService) deleteRecord(ctx context.Context, domain string, recordID string) (*Response, error) {
 	path := recordAction("Remove")
 
 	payload := newPayLoad(s.client.CommonParams)
@@ -293,19 +357,19 @@ func (s *DomainsService) DeleteRecord(domain string, recordID string) (*Response
 
 	returnedRecord := recordWrapper{}
 
-	res, err := s.client.post(path, payload, &returnedRecord)
+	res, err := s.client.postContext(ctx, path, payload, &returnedRecord)
 	if err != nil {
 		return res, err
 	}
 
-	if returnedRecord.Status.Code != "1" {
-		return nil, fmt.Errorf("could not delete record: %s", returnedRecord.Status.Message)
-	}
-
 	return res, nil
 }
 
 func (s *DomainsService) UpdateRecordStatus(domainID string, recordID string, status string) (*Response, error) {
+	return s.updateRecordStatus(context.Background(), domainID, recordID, status)
+}
+
+func (s *DomainsService) updateRecordStatus(ctx context.Context, domainID string, recordID string, status string) (*Response, error) {
 	path := recordAction("Status")
 	payload := newPayLoad(s.client.CommonParams)
 	payload.Add("domain_id", domainID)
@@ -314,44 +378,39 @@ func (s *DomainsService) UpdateRecordStatus(domainID string, recordID string, st
 
 	returnedRecord := recordWrapper{}
 
-	res, err := s.client.post(path, payload, &returnedRecord)
+	res, err := s.client.postContext(ctx, path, payload, &returnedRecord)
 	if err != nil {
 		return res, err
 	}
-	if returnedRecord.Status.Code != "1" {
-		return nil, fmt.Errorf("could not change record status: %s", returnedRecord.Status.Message)
-	}
 	return res, nil
 }
 
-func (s *DomainsService) GetRecordLine(domainGrade string, domainID string) ([]RecordLine, *Response, error) {
-	path := recordAction("Line")
+// SetRecordRemark sets the remark of a domain record, an empty remark clearing it.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#record-remark
+func (s *DomainsService) SetRecordRemark(domainID string, recordID string, remark string) (*Response, error) {
+	return s.setRecordRemark(context.Background(), domainID, recordID, remark)
+}
+
+func (s *DomainsService) setRecordRemark(ctx context.Context, domainID string, recordID string, remark string) (*Response, error) {
+	path := recordAction("Remark")
 	payload := newPayLoad(s.client.CommonParams)
-	payload.Set("domain_grade", domainGrade)
-	payload.Set("domain_id", domainID)
-	lines := linesWrapper{}
-	res, err := s.client.post(path, payload, &lines)
+	payload.Add("domain_id", domainID)
+	payload.Add("record_id", recordID)
+	payload.Add("remark", remark)
+
+	returnedRecord := recordWrapper{}
+
+	return s.client.postContext(ctx, path, payload, &returnedRecord)
+}
+
+// GetRecordLine lists the lines available to a domain of the given grade, in dnspod order.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#record-line
+func (s *DomainsService) GetRecordLine(domainGrade string, domainID string) ([]RecordLine, *Response, error) {
+	catalog, res, err := s.LineCatalog(domainGrade, domainID)
 	if err != nil {
 		return []RecordLine{}, res, err
 	}
-	if lines.Status.Code != "1" {
-		return []RecordLine{}, nil, fmt.Errorf("could not get record line: %s", lines.Status.Message)
-	}
-	var ret []RecordLine
-	for k, v := range lines.LineIDs {
-		s, ok := v.(string)
-		if !ok && k == "默认" {
-			ret = append(ret, RecordLine{
-				Line: k,
-				LineID: "0",
-			})
-		} else {
-			ret = append(ret, RecordLine{
-				Line: k,
-				LineID: s,
-			})
-		}
-	}
-	return ret, res, nil
+	return catalog.RecordLines(), res, nil
 }
-
@@ -1,6 +1,7 @@
 package dnspod
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	// "time"
@@ -12,6 +13,7 @@ import (
 // dnspod API docs: https://www.dnspod.cn/docs/domains.html
 type DomainsService struct {
 	client *Client
+	lines  lineCatalogs
 }
 
 type DomainInfo struct {
@@ -51,6 +53,7 @@ type Domain struct {
 	CNameSpeedUp     string `json:"cname_speedup,omitempty"`
 	Owner            string `json:"owner,omitempty"`
 	AuthToAnquanBao  bool   `json:"auth_to_anquanbao,omitempty"`
+	DNSPodNS         []string `json:"dnspod_ns,omitempty"`
 }
 
 type DomainQuery struct {
@@ -103,6 +106,10 @@ func domainAction(action string) string {
 //
 // dnspod API docs: https://www.dnspod.cn/docs/domains.html#domain-list
 func (s *DomainsService) List(query DomainQuery) (PaginationDomainList, *Response, error) {
+	return s.list(context.Background(), query)
+}
+
+func (s *DomainsService) list(ctx context.Context, query DomainQuery) (PaginationDomainList, *Response, error) {
 	path := domainAction("List")
 	returnedDomains := domainListWrapper{}
 
@@ -120,15 +127,11 @@ func (s *DomainsService) List(query DomainQuery) (PaginationDomainList, *Respons
 	if query.GroupId != "" {
 		payload.Set("group_id", query.GroupId)
 	}
-	res, err := s.client.post(path, payload, &returnedDomains)
+	res, err := s.client.postContext(ctx, path, payload, &returnedDomains)
 	if err != nil {
 		return PaginationDomainList{}, res, err
 	}
 
-	if returnedDomains.Status.Code != "1" {
-		return PaginationDomainList{}, nil, fmt.Errorf("Could not get domains: %s", returnedDomains.Status.Message)
-	}
-
 	var total int
 	if returnedDomains.Info == (DomainInfo{}) {
 		total = len(returnedDomains.Domains)
@@ -136,7 +139,6 @@ func (s *DomainsService) List(query DomainQuery) (PaginationDomainList, *Respons
 		total = getDomainListTotalSizeByType(query.Type, returnedDomains.Info)
 	}
 
-
 	return PaginationDomainList{
 		CurrentPage: query.CurrentPage,
 		PageSize: query.PageSize,
@@ -145,6 +147,32 @@ func (s *DomainsService) List(query DomainQuery) (PaginationDomainList, *Respons
 	}, res, nil
 }
 
+// ListAllDomains lists every domain of the account, following the pagination.
+func (s *DomainsService) ListAllDomains() ([]Domain, error) {
+	return s.listAllDomains(context.Background())
+}
+
+// ListAllDomainsContext is ListAllDomains with its requests bound to ctx.
+func (s *DomainsService) ListAllDomainsContext(ctx context.Context) ([]Domain, error) {
+	return s.listAllDomains(ctx)
+}
+
+func (s *DomainsService) listAllDomains(ctx context.Context) ([]Domain, error) {
+	const pageSize = 3000
+
+	var domains []Domain
+	for {
+		page, _, err := s.list(ctx, DomainQuery{CurrentPage: len(domains), PageSize: pageSize})
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, page.List...)
+		if len(page.List) == 0 || len(domains) >= page.Total {
+			return domains, nil
+		}
+	}
+}
+
 // Create a new domain.
 //
 // dnspod API docs: https://www.dnspod.cn/docs/domains.html#domain-create
@@ -162,10 +190,6 @@ func (s *DomainsService) Create(domainAttributes Domain) (Domain, *Response, err
 		return Domain{}, res, err
 	}
 
-	if returnedDomain.Status.Code != "1" {
-		return Domain{}, res, fmt.Errorf(returnedDomain.Status.Message)
-	}
-
 	return returnedDomain.Domain, res, nil
 }
 
@@ -83,6 +83,20 @@ func TestDomainsService_ListRecords_subdomain(t *testing.T) {
 	}
 }
 
+func TestDomainsService_ListRecords_noRecords(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"10","message":"No records"}}`)
+	})
+
+	_, _, err := client.Domains.ListRecords(RecordQuery{DomainID: "11223344", SubDomain: "none"})
+	if !IsNoRecords(err) {
+		t.Errorf("Domains.ListRecords returned error %v, want no records", err)
+	}
+}
+
 func TestDomainsService_CreateRecord(t *testing.T) {
 	setup()
 	defer teardown()
@@ -111,6 +125,36 @@ func TestDomainsService_CreateRecord(t *testing.T) {
 	}
 }
 
+func TestDomainsService_CreateRecord_remark(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("remark") != "" {
+			t.Errorf("Record.Create request has remark %q", r.FormValue("remark"))
+		}
+		fmt.Fprintf(w, `{"status": {"code":"1","message":""},"record":{"id":"26954449", "name":"www", "status":"enable"}}`)
+	})
+	mux.HandleFunc("/Record.Remark", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{
+			"login_token": "dnspod login token",
+			"domain_id":   "44146112",
+			"record_id":   "26954449",
+			"remark":      "web",
+		})
+		fmt.Fprint(w, `{"status": {"code":"1","message":""}}`)
+	})
+
+	record, _, err := client.Domains.CreateRecord("44146112", Record{Name: "www", Type: "A", Line: "默认", Value: "1.2.3.4", Remark: "web"})
+	if err != nil {
+		t.Fatalf("Domains.CreateRecord returned error: %v", err)
+	}
+	want := Record{ID: "26954449", Name: "www", Status: "enable", Remark: "web"}
+	if !reflect.DeepEqual(record, want) {
+		t.Errorf("Domains.CreateRecord returned %+v, want %+v", record, want)
+	}
+}
+
 func TestDomainsService_GetRecord(t *testing.T) {
 	setup()
 	defer teardown()
@@ -175,6 +219,23 @@ func TestDomainsService_DeleteRecord(t *testing.T) {
 	}
 }
 
+func TestDomainsService_DeleteRecord_invalidID(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"8","message":"Record id invalid"}}`)
+	})
+
+	_, err := client.Domains.DeleteRecord("44146112", "26954449")
+	if !IsInvalidRecordID(err) {
+		t.Errorf("Domains.DeleteRecord returned error %v, want invalid record ID", err)
+	}
+	if IsInvalidRecordID(nil) || IsNoRecords(err) {
+		t.Errorf("IsInvalidRecordID or IsNoRecords matched the wrong error")
+	}
+}
+
 func TestDomainsService_DeleteRecord_failed(t *testing.T) {
 	setup()
 	defer teardown()
@@ -253,3 +314,65 @@ func TestDomainsService_GetRecordLine(t *testing.T) {
 		t.Errorf("unexpect record line length: expect 2, real %d", len(recordLines))
 	}
 }
+
+func TestDomainsService_CreateRecord_weightAndURL(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testFormValues(t, r, values{
+			"login_token":       "dnspod login token",
+			"domain_id":         "44146112",
+			"sub_domain":        "go",
+			"record_type":       RecordTypeExplicitURL,
+			"value":             "https://example.com/",
+			"weight":            "20",
+			"url_redirect_code": "301",
+		})
+		fmt.Fprint(w, `{"status": {"code":"1","message":""},"record":{"id":"26954449", "name":"go", "weight": 20}}`)
+	})
+
+	record, _, err := client.Domains.CreateRecord("44146112", Record{
+		Name:            "go",
+		Type:            RecordTypeExplicitURL,
+		Value:           "https://example.com/",
+		Weight:          "20",
+		URLRedirectCode: "301",
+	})
+	if err != nil {
+		t.Fatalf("Domains.CreateRecord returned error: %v", err)
+	}
+
+	want := Record{ID: "26954449", Name: "go", Weight: "20"}
+	if !reflect.DeepEqual(record, want) {
+		t.Errorf("Domains.CreateRecord returned %+v, want %+v", record, want)
+	}
+}
+
+func TestValidateRecordOptions(t *testing.T) {
+	invalid := []Record{
+		{Type: "A", Weight: "101"},
+		{Type: "A", Weight: "heavy"},
+		{Type: "A", URLRedirectCode: "301"},
+		{Type: RecordTypeImplicitURL, URLRedirectCode: "301"},
+		{Type: RecordTypeExplicitURL, URLRedirectCode: "307"},
+		{Type: RecordTypeExplicitURL, URLTitle: "Example"},
+	}
+	for _, r := range invalid {
+		if err := validateRecordOptions(r); err == nil {
+			t.Errorf("validateRecordOptions(%+v) accepted invalid options", r)
+		}
+	}
+
+	valid := []Record{
+		{Type: "A", Weight: "0"},
+		{Type: RecordTypeExplicitURL, URLRedirectCode: "302", URLKeepPath: "yes"},
+		{Type: RecordTypeImplicitURL, URLTitle: "Example", URLKeywords: "example"},
+	}
+	for _, r := range valid {
+		if err := validateRecordOptions(r); err != nil {
+			t.Errorf("validateRecordOptions(%+v) returned %v", r, err)
+		}
+	}
+}
@@ -113,6 +113,27 @@ func TestDomainsService_List_Ambiguous_Value(t *testing.T) {
 	}
 }
 
+func TestDomainsService_ListAllDomains(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.List", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("offset") == "0" {
+			fmt.Fprint(w, `{"status": {"code":"1"},"info": {"domain_total": 2},"domains": [{"id": 1, "name": "example.com"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"status": {"code":"1"},"info": {"domain_total": 2},"domains": [{"id": 2, "name": "example.net"}]}`)
+	})
+
+	domains, err := client.Domains.ListAllDomains()
+	if err != nil {
+		t.Fatalf("Domains.ListAllDomains returned error: %v", err)
+	}
+	if len(domains) != 2 || domains[1].Name != "example.net" {
+		t.Errorf("Domains.ListAllDomains returned %+v", domains)
+	}
+}
+
 func TestDomainsService_Create(t *testing.T) {
 	setup()
 	defer teardown()
@@ -0,0 +1,264 @@
+package dnspod
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DriftKind is the kind of divergence of a Drift.
+type DriftKind string
+
+const (
+	DriftMissing  DriftKind = "missing"  // in the source, not live
+	DriftExtra    DriftKind = "extra"    // live, not in the source
+	DriftValue    DriftKind = "value"    // the value, or MX priority, differs
+	DriftTTL      DriftKind = "ttl"      // the TTL differs
+	DriftWeight   DriftKind = "weight"   // the weight differs
+	DriftRemark   DriftKind = "remark"   // the remark differs
+	DriftLine     DriftKind = "line"     // the line differs
+	DriftDisabled DriftKind = "disabled" // the record is disabled while enabled in the source, or the reverse
+)
+
+// Drift is a divergence of the live records of a domain from the source of truth.
+type Drift struct {
+	Kind   DriftKind `json:"kind"`
+	Name   string    `json:"name"`
+	Type   string    `json:"type"`
+	Line   string    `json:"line"`
+	Want   *Record   `json:"want,omitempty"` // nil for DriftExtra
+	Have   *Record   `json:"have,omitempty"` // nil for DriftMissing
+	Detail string    `json:"detail"`
+}
+
+// DriftReport lists the drifts of the records of a domain, e.g.
+//
+//	report, err := client.Domains.DriftReport("1", desired)
+//	...
+//	report.WriteJUnit(f)
+//	if report.HasDrift() {
+//		os.Exit(1)
+//	}
+type DriftReport struct {
+	DomainID string  `json:"domain_id"`
+	Checked  int     `json:"checked"` // source records checked
+	Drifts   []Drift `json:"drifts"`
+}
+
+// NewDriftReport compares the live records of a domain with the desired ones. The apex NS
+// records, managed by dnspod, are left out, and so are the attributes left empty in desired.
+//
+// Records are matched by name and type, then by line and value: a desired record matching
+// a live one on its value alone has a line drift, and on its line alone a value drift.
+// Values and attributes compare as in DiffRecords, so that a report without drift
+// means an empty Plan.
+func NewDriftReport(domainID string, live, desired []Record) *DriftReport {
+	report := &DriftReport{DomainID: domainID, Drifts: []Drift{}}
+
+	type rrset struct{ want, have []int }
+	sets := map[string]*rrset{}
+	set := func(r Record) *rrset {
+		key := strings.ToLower(r.Name) + "|" + strings.ToUpper(r.Type)
+		if sets[key] == nil {
+			sets[key] = &rrset{}
+		}
+		return sets[key]
+	}
+	for i, r := range desired {
+		if !systemRecord(r) {
+			set(r).want = append(set(r).want, i)
+			report.Checked++
+		}
+	}
+	for i, r := range live {
+		if !systemRecord(r) {
+			set(r).have = append(set(r).have, i)
+		}
+	}
+
+	// Pair the records of each set, the closest matches first.
+	pairs := map[int]int{} // live by desired index
+	paired := map[int]bool{}
+	for _, s := range sets {
+		for _, match := range []func(want, have Record) bool{
+			func(want, have Record) bool {
+				return recordLine(want) == recordLine(have) && recordValue(want) == recordValue(have)
+			},
+			func(want, have Record) bool { return recordValue(want) == recordValue(have) },
+			func(want, have Record) bool { return recordLine(want) == recordLine(have) },
+		} {
+			for _, w := range s.want {
+				if _, ok := pairs[w]; ok {
+					continue
+				}
+				for _, h := range s.have {
+					if !paired[h] && match(desired[w], live[h]) {
+						pairs[w], paired[h] = h, true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	for i, want := range desired {
+		if systemRecord(want) {
+			continue
+		}
+		want := want
+		h, ok := pairs[i]
+		if !ok {
+			report.add(DriftMissing, &want, nil, "not live")
+			continue
+		}
+		have := live[h]
+		if recordLine(have) != recordLine(want) {
+			report.add(DriftLine, &want, &have, fmt.Sprintf("line %s, want %s", recordLine(have), recordLine(want)))
+		}
+		if recordValue(have) != recordValue(want) {
+			report.add(DriftValue, &want, &have, fmt.Sprintf("value %q, want %q", have.Value, want.Value))
+		} else if want.MX != "" && recordMX(have) != recordMX(want) {
+			report.add(DriftValue, &want, &have, fmt.Sprintf("mx %s, want %s", have.MX, want.MX))
+		}
+		if want.TTL != "" && have.TTL != want.TTL {
+			report.add(DriftTTL, &want, &have, fmt.Sprintf("ttl %s, want %s", have.TTL, want.TTL))
+		}
+		if want.Weight != "" && have.Weight != want.Weight {
+			report.add(DriftWeight, &want, &have, fmt.Sprintf("weight %s, want %s", have.Weight, want.Weight))
+		}
+		if want.Remark != "" && have.Remark != want.Remark {
+			report.add(DriftRemark, &want, &have, fmt.Sprintf("remark %q, want %q", have.Remark, want.Remark))
+		}
+		if RecordEnabled(have) != RecordEnabled(want) {
+			report.add(DriftDisabled, &want, &have, fmt.Sprintf("%sd, want %sd", recordStatus(have), recordStatus(want)))
+		}
+	}
+	for i, have := range live {
+		if !systemRecord(have) && !paired[i] {
+			have := have
+			report.add(DriftExtra, nil, &have, "not in the source")
+		}
+	}
+	return report
+}
+
+func (r *DriftReport) add(kind DriftKind, want, have *Record, detail string) {
+	record := want
+	if record == nil {
+		record = have
+	}
+	r.Drifts = append(r.Drifts, Drift{
+		Kind:   kind,
+		Name:   record.Name,
+		Type:   strings.ToUpper(record.Type),
+		Line:   recordLine(*record),
+		Want:   want,
+		Have:   have,
+		Detail: detail,
+	})
+}
+
+// DriftReport lists the records of a domain and compares them with the desired ones.
+func (s *DomainsService) DriftReport(domainID string, desired []Record) (*DriftReport, error) {
+	live, err := s.ListAllRecords(domainID)
+	if err != nil {
+		return nil, err
+	}
+	if desired, err = s.resolveRecordLines(context.Background(), domainID, desired); err != nil {
+		return nil, err
+	}
+	return NewDriftReport(domainID, live, desired), nil
+}
+
+// HasDrift reports whether the live records diverge from the source.
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Drifts) > 0
+}
+
+// WriteJSON writes the report as JSON.
+func (r *DriftReport) WriteJSON(w io.Writer) error {
+	bs, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(bs, '\n'))
+	return err
+}
+
+// WriteJUnit writes the report as JUnit XML, see WriteDriftJUnit.
+func (r *DriftReport) WriteJUnit(w io.Writer) error {
+	return WriteDriftJUnit(w, r)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteDriftJUnit writes reports as JUnit XML, a test suite per domain and a failed
+// test case per drift, so that CI fails on drift. A domain without drift has a single
+// test case, passed.
+func WriteDriftJUnit(w io.Writer, reports ...*DriftReport) error {
+	suites := junitTestSuites{Name: "dnspod drift"}
+	for _, r := range reports {
+		suite := junitTestSuite{Name: "domain " + r.DomainID}
+		for _, d := range r.Drifts {
+			record := d.Want
+			if record == nil {
+				record = d.Have
+			}
+			suite.Cases = append(suite.Cases, junitTestCase{
+				ClassName: suite.Name,
+				Name:      fmt.Sprintf("%s %s %s %s", d.Kind, recordName(d.Name), d.Type, d.Line),
+				Failure:   &junitFailure{Type: string(d.Kind), Message: d.Detail, Text: formatRecord(*record)},
+			})
+		}
+		suite.Failures = len(suite.Cases)
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{ClassName: suite.Name, Name: fmt.Sprintf("%d records in sync", r.Checked)})
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	bs, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, bs)
+	return err
+}
+
+// recordName returns the name of a record, @ for the apex.
+func recordName(name string) string {
+	if name == "" {
+		return "@"
+	}
+	return name
+}
@@ -0,0 +1,125 @@
+package dnspod
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewDriftReport(t *testing.T) {
+	live := []Record{
+		{ID: "1", Name: "@", Type: "NS", Value: "f1g1ns1.dnspod.net.", Line: DefaultLine},
+		{ID: "2", Name: "www", Type: "A", Value: "1.1.1.1", Line: DefaultLine, TTL: "600", Enabled: "1"},
+		{ID: "3", Name: "www", Type: "A", Value: "3.3.3.3", Line: DefaultLine, TTL: "600", Enabled: "1"},
+		{ID: "4", Name: "api", Type: "CNAME", Value: "LB.example.net.", Line: "电信", TTL: "300", Enabled: "0"},
+		{ID: "5", Name: "@", Type: "MX", Value: "mail.example.com.", MX: "20", Line: DefaultLine, TTL: "600", Enabled: "1"},
+		{ID: "6", Name: "old", Type: "A", Value: "9.9.9.9", Line: DefaultLine, TTL: "600", Enabled: "1"},
+	}
+	desired := []Record{
+		{Name: "www", Type: "A", Value: "1.1.1.1", TTL: "600"},
+		{Name: "www", Type: "A", Value: "2.2.2.2"},
+		{Name: "api", Type: "CNAME", Value: "lb.example.net", Line: DefaultLine, TTL: "600"},
+		{Name: "@", Type: "MX", Value: "mail.example.com.", MX: "10"},
+		{Name: "new", Type: "TXT", Value: "hello"},
+	}
+
+	report := NewDriftReport("1", live, desired)
+
+	var got []string
+	for _, d := range report.Drifts {
+		got = append(got, fmt.Sprintf("%s %s %s: %s", d.Kind, d.Name, d.Type, d.Detail))
+	}
+	want := []string{
+		`value www A: value "3.3.3.3", want "2.2.2.2"`,
+		"line api CNAME: line 电信, want 默认",
+		"ttl api CNAME: ttl 300, want 600",
+		"disabled api CNAME: disabled, want enabled",
+		"value @ MX: mx 20, want 10",
+		"missing new TXT: not live",
+		"extra old A: not in the source",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("NewDriftReport returned\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if report.Checked != 5 || !report.HasDrift() {
+		t.Errorf("NewDriftReport returned %d records checked, drift %v", report.Checked, report.HasDrift())
+	}
+	if report.Drifts[6].Want != nil || report.Drifts[6].Have.ID != "6" {
+		t.Errorf("NewDriftReport returned extra drift %+v", report.Drifts[6])
+	}
+}
+
+func TestNewDriftReport_agreesWithPlan(t *testing.T) {
+	live := []Record{
+		{ID: "2", Name: "www", Type: "CNAME", Value: "lb.example.net", Line: DefaultLine, TTL: "600", Enabled: "1"},
+		{ID: "3", Name: "api", Type: "A", Value: "1.1.1.1", Line: DefaultLine, TTL: "600", Weight: "10", Enabled: "1"},
+	}
+	tests := []struct {
+		desired []Record
+		drift   bool
+	}{
+		{[]Record{
+			{Name: "www", Type: "CNAME", Value: "LB.example.net.", Line: DefaultLine, TTL: "600"},
+			{Name: "api", Type: "A", Value: "1.1.1.1", Line: DefaultLine, TTL: "600", Weight: "10"},
+		}, false},
+		{[]Record{
+			{Name: "www", Type: "CNAME", Value: "lb.example.net.", Line: DefaultLine, TTL: "600"},
+			{Name: "api", Type: "A", Value: "1.1.1.1", Line: DefaultLine, TTL: "600", Weight: "20"},
+		}, true},
+	}
+	for _, tt := range tests {
+		report, plan := NewDriftReport("1", live, tt.desired), NewPlan("1", live, tt.desired)
+		if report.HasDrift() != tt.drift || plan.Empty() == tt.drift {
+			t.Errorf("NewDriftReport returned %+v and NewPlan %+v, want drift %v", report.Drifts, plan.Entries, tt.drift)
+		}
+	}
+}
+
+func TestDriftReport_WriteJUnit(t *testing.T) {
+	inSync := &DriftReport{DomainID: "1", Checked: 3, Drifts: []Drift{}}
+	drifted := NewDriftReport("2", nil, []Record{{Name: "www", Type: "A", Value: "1.1.1.1"}})
+
+	var b bytes.Buffer
+	if err := WriteDriftJUnit(&b, inSync, drifted); err != nil {
+		t.Fatalf("WriteDriftJUnit returned error: %v", err)
+	}
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<testsuites name="dnspod drift" tests="2" failures="1">`,
+		`<testsuite name="domain 1" tests="1" failures="0">`,
+		`<testcase classname="domain 1" name="3 records in sync"></testcase>`,
+		`<testcase classname="domain 2" name="missing www A 默认">`,
+		`<failure type="missing" message="not live">www IN A 1.1.1.1 ; 默认</failure>`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteDriftJUnit is missing %q:\n%s", want, b.String())
+		}
+	}
+
+	b.Reset()
+	drifted.WriteJSON(&b)
+	if !strings.Contains(b.String(), `"kind": "missing"`) || !strings.Contains(b.String(), `"domain_id": "2"`) {
+		t.Errorf("DriftReport.WriteJSON wrote:\n%s", b.String())
+	}
+}
+
+func TestDomainsService_DriftReport(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "domain_id": "1", "offset": "0", "length": "3000"})
+		fmt.Fprint(w, `{"status": {"code":"1"},"info": {"record_total": "1"},"records": [
+			{"id":"2","name":"www","type":"A","value":"1.1.1.1","line":"默认","ttl":"600","enabled":"1"}]}`)
+	})
+
+	report, err := client.Domains.DriftReport("1", []Record{{Name: "www", Type: "A", Value: "1.1.1.1", TTL: "600"}})
+	if err != nil {
+		t.Fatalf("Domains.DriftReport returned error: %v", err)
+	}
+	if report.HasDrift() {
+		t.Errorf("Domains.DriftReport returned drifts %+v", report.Drifts)
+	}
+}
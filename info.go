@@ -0,0 +1,59 @@
+package dnspod
+
+import (
+	"context"
+	"time"
+)
+
+func init() {
+	registerReadOnly("Info.Version")
+}
+
+// PingResult reports a successful round trip to the dnspod API.
+type PingResult struct {
+	APIVersion string
+	Latency    time.Duration // latency of the whole probe, Info.Version then User.Detail
+	User       User          // user the token authenticates
+}
+
+// Version fetches the version of the dnspod API.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/info.html#get-api-version
+func (c *Client) Version(ctx context.Context) (string, *Response, error) {
+	payload := newPayLoad(c.CommonParams)
+	wrapper := struct {
+		Status Status `json:"status"`
+	}{}
+
+	res, err := c.DoContext(ctx, "POST", "Info.Version", payload, &wrapper)
+	if err != nil {
+		return "", res, err
+	}
+	return wrapper.Status.Message, res, nil
+}
+
+// Ping checks that the API is reachable and that the token is valid,
+// without touching any zone. Its calls bypass the Cache, so that a revoked
+// token is reported as soon as dnspod rejects it. It is meant for readiness checks:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+//	defer cancel()
+//	_, err := client.Ping(ctx)
+func (c *Client) Ping(ctx context.Context) (PingResult, error) {
+	ctx = NoCache(ctx)
+	start := time.Now()
+	version, _, err := c.Version(ctx)
+	if err != nil {
+		return PingResult{}, err
+	}
+	result := PingResult{APIVersion: version}
+
+	wrapper := userWrapper{}
+	_, err = c.DoContext(ctx, "POST", userAction("Detail"), newPayLoad(c.CommonParams), &wrapper)
+	result.Latency = time.Since(start)
+	if err != nil {
+		return result, err
+	}
+	result.User = wrapper.Info.User
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package dnspod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_Ping(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Info.Version", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprint(w, `{"status": {"code":"1","message":"4.6","created_at":"2015-01-18 20:07:29"}}`)
+	})
+	mux.HandleFunc("/User.Detail", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"info": {"user": {"id": "625033", "email": "api@dnspod.com"}}}`)
+	})
+
+	result, err := client.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping returned error: %v", err)
+	}
+	testString(t, "Ping APIVersion", result.APIVersion, "4.6")
+	testString(t, "Ping User.Email", result.User.Email, "api@dnspod.com")
+	if result.Latency <= 0 {
+		t.Errorf("Ping Latency = %v, want a positive duration", result.Latency)
+	}
+}
+
+func TestClient_Ping_invalidToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Info.Version", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":"4.6"}}`)
+	})
+	mux.HandleFunc("/User.Detail", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"-1","message":"Login fail, please check login info."}}`)
+	})
+
+	result, err := client.Ping(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status.Code != "-1" {
+		t.Fatalf("Ping returned %v, want the login failure", err)
+	}
+	testString(t, "Ping APIVersion", result.APIVersion, "4.6")
+}
+
+func TestClient_Ping_cache(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Info.Version", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":"4.6"}}`)
+	})
+	revoked := false
+	mux.HandleFunc("/User.Detail", func(w http.ResponseWriter, r *http.Request) {
+		if revoked {
+			fmt.Fprint(w, `{"status": {"code":"-1","message":"Login fail, please check login info."}}`)
+			return
+		}
+		fmt.Fprint(w, `{"status": {"code":"1"},"info": {"user": {"id": "625033", "email": "api@dnspod.com"}}}`)
+	})
+
+	client.Use(NewCache(NewLRUCache(16), time.Minute).Middleware)
+	if _, _, err := client.Users.Detail(); err != nil {
+		t.Fatalf("Users.Detail returned error: %v", err)
+	}
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping returned error: %v", err)
+	}
+
+	revoked = true
+	if _, err := client.Ping(context.Background()); err == nil {
+		t.Errorf("Ping of a revoked token returned no error")
+	}
+}
+
+func TestClient_Ping_timeout(t *testing.T) {
+	setup()
+	defer teardown()
+
+	release := make(chan struct{})
+	defer close(release)
+	mux.HandleFunc("/Info.Version", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Ping(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Ping returned %v, want context.DeadlineExceeded", err)
+	}
+}
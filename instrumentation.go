@@ -0,0 +1,114 @@
+package dnspod
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Tracer starts a span for every dnspod action performed by the Client.
+// It is small enough to be adapted to OpenTelemetry or any other tracing library.
+type Tracer interface {
+	Start(ctx context.Context, action string) (context.Context, Span)
+}
+
+// Span is a single traced dnspod action.
+type Span interface {
+	SetAttribute(key string, value any)
+	End(err error)
+}
+
+// Meter records metrics about the dnspod actions performed by the Client.
+// It is small enough to be adapted to Prometheus or any other metrics library.
+type Meter interface {
+	// ObserveLatency records the duration of an action, labelled with the dnspod status code.
+	ObserveLatency(action string, code string, d time.Duration)
+
+	// IncError counts an action that failed, either with a dnspod status code
+	// different from "1" or, when code is empty, at the transport level.
+	IncError(action string, code string)
+}
+
+// Span attribute keys set by the Client.
+const (
+	AttributeAction     = "dnspod.action"
+	AttributeDomainID   = "dnspod.domain_id"
+	AttributeStatusCode = "http.status_code"
+	AttributeCode       = "dnspod.status_code"
+	AttributeRetryCount = "dnspod.retry_count"
+)
+
+type attemptsKey struct{}
+
+// Transport is an http.RoundTripper counting the HTTP attempts made for each action,
+// so that retries performed below the Client are reported on its span.
+// Install it as the innermost layer of the Client HttpClient transport chain.
+type Transport struct {
+	// Base is the underlying round tripper, http.DefaultTransport if nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if attempts, ok := req.Context().Value(attemptsKey{}).(*int32); ok {
+		atomic.AddInt32(attempts, 1)
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// instrumentation is the built-in middleware reporting every API call to the configured Tracer and Meter.
+func (c *Client) instrumentation(next Handler) Handler {
+	return HandlerFunc(func(req *Request) (*Result, error) {
+		if c.Tracer == nil && c.Meter == nil {
+			return next.Handle(req)
+		}
+
+		ctx := req.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		attempts := new(int32)
+		ctx = context.WithValue(ctx, attemptsKey{}, attempts)
+
+		var span Span
+		if c.Tracer != nil {
+			ctx, span = c.Tracer.Start(ctx, req.Action)
+			span.SetAttribute(AttributeAction, req.Action)
+			if domainID := req.Payload.Get("domain_id"); domainID != "" {
+				span.SetAttribute(AttributeDomainID, domainID)
+			}
+		}
+
+		start := time.Now()
+		inner := *req
+		inner.Context = ctx
+		result, err := next.Handle(&inner)
+		entry := newLogEntry(req, result, err, time.Since(start))
+
+		if span != nil {
+			span.SetAttribute(AttributeStatusCode, entry.StatusCode)
+			if entry.Code != "" {
+				span.SetAttribute(AttributeCode, entry.Code)
+			}
+			retries := 0
+			if n := atomic.LoadInt32(attempts); n > 1 {
+				retries = int(n - 1)
+			}
+			span.SetAttribute(AttributeRetryCount, retries)
+			span.End(err)
+		}
+		if c.Meter != nil {
+			c.Meter.ObserveLatency(req.Action, entry.Code, entry.Latency)
+			if err != nil {
+				c.Meter.IncError(req.Action, entry.Code)
+			}
+		}
+
+		return result, err
+	})
+}
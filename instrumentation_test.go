@@ -0,0 +1,118 @@
+package dnspod
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testSpan struct {
+	action     string
+	attributes map[string]any
+	ended      bool
+}
+
+func (s *testSpan) SetAttribute(key string, value any) { s.attributes[key] = value }
+func (s *testSpan) End(err error)                      { s.ended = true }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, action string) (context.Context, Span) {
+	span := &testSpan{action: action, attributes: map[string]any{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+type testMeter struct {
+	latencies []string
+	errors    []string
+}
+
+func (m *testMeter) ObserveLatency(action string, code string, d time.Duration) {
+	m.latencies = append(m.latencies, action+":"+code)
+}
+
+func (m *testMeter) IncError(action string, code string) {
+	m.errors = append(m.errors, action+":"+code)
+}
+
+// retryTransport retries every request once, as a retrying HTTP client would.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return t.base.RoundTrip(req)
+}
+
+func TestClient_Instrumentation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"8","message":"Record id invalid"}}`)
+	})
+
+	tracer := &testTracer{}
+	meter := &testMeter{}
+	client.Tracer = tracer
+	client.Meter = meter
+	client.HttpClient.Transport = retryTransport{base: &Transport{}}
+
+	client.Domains.DeleteRecord("44146112", "26954449")
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Tracer started %d spans, want 1", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if !span.ended {
+		t.Errorf("span was not ended")
+	}
+	want := map[string]any{
+		AttributeAction:     "Record.Remove",
+		AttributeDomainID:   "44146112",
+		AttributeStatusCode: http.StatusOK,
+		AttributeCode:       "8",
+		AttributeRetryCount: 1,
+	}
+	if !reflect.DeepEqual(span.attributes, want) {
+		t.Errorf("span attributes = %v, want %v", span.attributes, want)
+	}
+
+	if !reflect.DeepEqual(meter.latencies, []string{"Record.Remove:8"}) {
+		t.Errorf("Meter latencies = %v", meter.latencies)
+	}
+	if !reflect.DeepEqual(meter.errors, []string{"Record.Remove:8"}) {
+		t.Errorf("Meter errors = %v", meter.errors)
+	}
+}
+
+func TestClient_Instrumentation_nilContext(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Info.Version", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":"4.6"}}`)
+	})
+
+	tracer := &testTracer{}
+	client.Tracer = tracer
+
+	req := &Request{Method: "POST", Action: "Info.Version", Payload: newPayLoad(client.CommonParams)}
+	if _, err := client.handler().Handle(req); err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+	if len(tracer.spans) != 1 {
+		t.Errorf("Tracer started %d spans, want 1", len(tracer.spans))
+	}
+}
@@ -0,0 +1,234 @@
+// Package lego implements a DNS-01 challenge provider for lego, e.g.
+//
+//	provider, err := lego.NewDNSProvider() // reads DNSPOD_API_KEY
+//	...
+//	err = client.Challenge.SetDNS01Provider(provider)
+//
+// DNSProvider satisfies the challenge.Provider and challenge.ProviderTimeout
+// interfaces of lego without depending on it.
+package lego
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decker502/dnspod-go"
+)
+
+// Environment variables read by NewDefaultConfig and NewDNSProvider,
+// the ones of the lego dnspod provider.
+const (
+	EnvAPIKey             = "DNSPOD_API_KEY"
+	EnvTTL                = "DNSPOD_TTL"
+	EnvPropagationTimeout = "DNSPOD_PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = "DNSPOD_POLLING_INTERVAL"
+)
+
+// Config configures a DNSProvider.
+type Config struct {
+	// LoginToken is the "ID,Token" API token.
+	LoginToken string
+
+	// TTL of the challenge records, in seconds.
+	TTL int
+
+	// PropagationTimeout and PollingInterval are returned by DNSProvider.Timeout:
+	// lego checks every PollingInterval that the challenge record is served,
+	// giving up after PropagationTimeout.
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+
+	// Client, if set, is used instead of a client authenticated with LoginToken.
+	Client *dnspod.Client
+}
+
+// NewDefaultConfig returns the default configuration, overridden by the environment.
+func NewDefaultConfig() *Config {
+	return &Config{
+		TTL:                envInt(EnvTTL, 600),
+		PropagationTimeout: time.Duration(envInt(EnvPropagationTimeout, 60)) * time.Second,
+		PollingInterval:    time.Duration(envInt(EnvPollingInterval, 2)) * time.Second,
+	}
+}
+
+func envInt(name string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+// DNSProvider presents DNS-01 challenges as TXT records of dnspod domains.
+// It is safe for concurrent use, including for several challenges of the same
+// domain, e.g. for example.com and *.example.com.
+type DNSProvider struct {
+	config *Config
+	client *dnspod.Client
+
+	mu      sync.Mutex
+	records map[string]challengeRecord // by challenge FQDN and token
+	zones   map[string]dnspod.Domain   // by challenge FQDN
+}
+
+// challengeRecord is a TXT record created by Present.
+type challengeRecord struct {
+	domainID string
+	recordID string
+}
+
+// NewDNSProvider returns a DNSProvider configured from the environment.
+func NewDNSProvider() (*DNSProvider, error) {
+	config := NewDefaultConfig()
+	config.LoginToken = os.Getenv(EnvAPIKey)
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig returns a DNSProvider for the given configuration.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("dnspod: the configuration of the DNS provider is nil")
+	}
+	client := config.Client
+	if client == nil {
+		if config.LoginToken == "" {
+			return nil, fmt.Errorf("dnspod: credentials missing, set %s", EnvAPIKey)
+		}
+		client = dnspod.NewClient(dnspod.CommonParams{LoginToken: config.LoginToken})
+	}
+	return &DNSProvider{config: config, client: client, records: map[string]challengeRecord{}, zones: map[string]dnspod.Domain{}}, nil
+}
+
+// Timeout returns the propagation timeout and polling interval of the challenges.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+// Present creates the TXT record of a DNS-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value := ChallengeRecord(domain, keyAuth)
+	zone, err := d.zone(fqdn)
+	if err != nil {
+		return err
+	}
+
+	record := dnspod.Record{
+		Name:  subdomain(fqdn, zone.Name),
+		Type:  "TXT",
+		Line:  dnspod.DefaultLine,
+		Value: value,
+		TTL:   strconv.Itoa(d.config.TTL),
+	}
+	created, _, err := d.client.Domains.CreateRecord(zone.ID, record)
+	if err != nil {
+		return fmt.Errorf("dnspod: create the challenge record of %s: %w", domain, err)
+	}
+
+	d.mu.Lock()
+	d.records[fqdn+"|"+token] = challengeRecord{domainID: zone.ID, recordID: created.ID}
+	d.mu.Unlock()
+	return nil
+}
+
+// CleanUp deletes the TXT record created by Present. Records not created by this
+// provider, e.g. before a restart, are looked up by name and value, and records
+// already deleted are left as cleaned up.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, value := ChallengeRecord(domain, keyAuth)
+	key := fqdn + "|" + token
+
+	d.mu.Lock()
+	record, ok := d.records[key]
+	d.mu.Unlock()
+	if !ok {
+		var err error
+		if record, err = d.find(fqdn, value); err != nil {
+			return err
+		}
+		if record.recordID == "" {
+			return nil
+		}
+	}
+
+	if _, err := d.client.Domains.DeleteRecord(record.domainID, record.recordID); err != nil && !dnspod.IsInvalidRecordID(err) {
+		return fmt.Errorf("dnspod: delete the challenge record of %s: %w", domain, err)
+	}
+	d.mu.Lock()
+	delete(d.records, key)
+	d.mu.Unlock()
+	return nil
+}
+
+// find looks up a challenge record by name and value.
+func (d *DNSProvider) find(fqdn, value string) (challengeRecord, error) {
+	zone, err := d.zone(fqdn)
+	if err != nil {
+		return challengeRecord{}, err
+	}
+	records, _, err := d.client.Domains.ListRecords(dnspod.RecordQuery{DomainID: zone.ID, SubDomain: subdomain(fqdn, zone.Name)})
+	if dnspod.IsNoRecords(err) {
+		return challengeRecord{}, nil
+	}
+	if err != nil {
+		return challengeRecord{}, fmt.Errorf("dnspod: list the records of %s: %w", zone.Name, err)
+	}
+	for _, r := range records.List {
+		if r.Type == "TXT" && r.Value == value {
+			return challengeRecord{domainID: zone.ID, recordID: r.ID}, nil
+		}
+	}
+	return challengeRecord{}, nil
+}
+
+// zone returns the most specific domain of the account holding fqdn, caching it
+// for the CleanUp and later challenges of the same name.
+func (d *DNSProvider) zone(fqdn string) (dnspod.Domain, error) {
+	d.mu.Lock()
+	zone, ok := d.zones[fqdn]
+	d.mu.Unlock()
+	if ok {
+		return zone, nil
+	}
+
+	domains, err := d.client.Domains.ListAllDomains()
+	if err != nil {
+		return dnspod.Domain{}, fmt.Errorf("dnspod: list domains: %w", err)
+	}
+	name := strings.TrimSuffix(fqdn, ".")
+	for _, domain := range domains {
+		if (name == domain.Name || strings.HasSuffix(name, "."+domain.Name)) && len(domain.Name) > len(zone.Name) {
+			zone = domain
+		}
+	}
+	if zone.ID == "" {
+		return zone, fmt.Errorf("dnspod: no domain of the account holds %s", fqdn)
+	}
+	d.mu.Lock()
+	d.zones[fqdn] = zone
+	d.mu.Unlock()
+	return zone, nil
+}
+
+// subdomain returns the name of fqdn relative to a domain.
+func subdomain(fqdn, domain string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(fqdn, "."), domain)
+	if name == "" {
+		return "@"
+	}
+	return strings.TrimSuffix(name, ".")
+}
+
+// ChallengeRecord returns the FQDN and value of the TXT record of a DNS-01
+// challenge, as computed by lego's dns01.GetRecord.
+func ChallengeRecord(domain, keyAuth string) (fqdn, value string) {
+	sum := sha256.Sum256([]byte(keyAuth))
+	value = base64.RawURLEncoding.EncodeToString(sum[:])
+	domain = strings.TrimSuffix(strings.TrimPrefix(domain, "*."), ".")
+	return "_acme-challenge." + strings.ToLower(domain) + ".", value
+}
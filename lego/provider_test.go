@@ -0,0 +1,167 @@
+package lego
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decker502/dnspod-go/dnspodtest"
+)
+
+func newProvider(t *testing.T) (*DNSProvider, *dnspodtest.Server) {
+	t.Helper()
+	srv := dnspodtest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddDomain("example.com")
+	srv.AddDomain("sub.example.com")
+
+	config := NewDefaultConfig()
+	config.Client = srv.Client()
+	provider, err := NewDNSProviderConfig(config)
+	if err != nil {
+		t.Fatalf("NewDNSProviderConfig returned error: %v", err)
+	}
+	return provider, srv
+}
+
+// challenges returns the TXT records of a domain.
+func challenges(srv *dnspodtest.Server, domain string) []string {
+	var values []string
+	for _, r := range srv.Records(domain) {
+		if r.Type == "TXT" {
+			values = append(values, r.Name+" "+r.Value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+func TestChallengeRecord(t *testing.T) {
+	fqdn, value := ChallengeRecord("*.Example.com", "token.key")
+	if fqdn != "_acme-challenge.example.com." {
+		t.Errorf("fqdn = %q", fqdn)
+	}
+	// base64url(sha256("token.key")), without padding
+	if value != "BBQUgcxf5weD7GT5jGRqmNsvAZXUWBoqPngIzDdoBFs" {
+		t.Errorf("value = %q", value)
+	}
+}
+
+func TestDNSProvider_ConcurrentChallenges(t *testing.T) {
+	provider, srv := newProvider(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i, domain := range []string{"example.com", "*.example.com", "www.example.com", "api.sub.example.com"} {
+		wg.Add(1)
+		go func(i int, domain string) {
+			defer wg.Done()
+			errs <- provider.Present(domain, fmt.Sprintf("token%d", i), fmt.Sprintf("token%d.key", i))
+		}(i, domain)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Present returned error: %v", err)
+		}
+	}
+
+	if got := challenges(srv, "example.com"); len(got) != 3 || got[0][:15] != "_acme-challenge" || got[2][:19] != "_acme-challenge.www" {
+		t.Errorf("example.com challenges = %q", got)
+	}
+	if got := challenges(srv, "sub.example.com"); len(got) != 1 || got[0][:19] != "_acme-challenge.api" {
+		t.Errorf("sub.example.com challenges = %q", got)
+	}
+
+	// Cleaning up the wildcard challenge leaves the one of the apex in place.
+	if err := provider.CleanUp("*.example.com", "token1", "token1.key"); err != nil {
+		t.Fatalf("CleanUp returned error: %v", err)
+	}
+	_, apex := ChallengeRecord("example.com", "token0.key")
+	got := challenges(srv, "example.com")
+	if len(got) != 2 || (got[0] != "_acme-challenge "+apex && got[1] != "_acme-challenge "+apex) {
+		t.Errorf("example.com challenges after CleanUp = %q", got)
+	}
+}
+
+func TestDNSProvider_CleanUpUntracked(t *testing.T) {
+	provider, srv := newProvider(t)
+	if err := provider.Present("example.com", "token", "token.key"); err != nil {
+		t.Fatalf("Present returned error: %v", err)
+	}
+
+	// A new provider, e.g. after a restart, finds the record by name and value.
+	other, err := NewDNSProviderConfig(&Config{Client: srv.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.CleanUp("example.com", "token", "token.key"); err != nil {
+		t.Fatalf("CleanUp returned error: %v", err)
+	}
+	if got := challenges(srv, "example.com"); len(got) != 0 {
+		t.Errorf("challenges after CleanUp = %q", got)
+	}
+	if err := other.CleanUp("example.com", "token", "token.key"); err != nil {
+		t.Errorf("CleanUp of a missing record returned error: %v", err)
+	}
+}
+
+func TestDNSProvider_CleanUpDeleted(t *testing.T) {
+	provider, srv := newProvider(t)
+	srv.PageLimit = 1
+	if err := provider.Present("www.sub.example.com", "token", "token.key"); err != nil {
+		t.Fatalf("Present returned error: %v", err)
+	}
+
+	// The tracked record is deleted by someone else: CleanUp has nothing left to do.
+	for _, r := range provider.records {
+		if _, err := srv.Client().Domains.DeleteRecord(r.domainID, r.recordID); err != nil {
+			t.Fatalf("DeleteRecord returned error: %v", err)
+		}
+	}
+	if err := provider.CleanUp("www.sub.example.com", "token", "token.key"); err != nil {
+		t.Errorf("CleanUp of a record already deleted returned error: %v", err)
+	}
+	if len(provider.records) != 0 {
+		t.Errorf("CleanUp of a record already deleted kept tracking it: %v", provider.records)
+	}
+	if n := srv.Calls("Domain.List"); n != 2 {
+		t.Errorf("Present and CleanUp made %d Domain.List calls, want the 2 pages of a single listing", n)
+	}
+}
+
+func TestDNSProvider_UnknownDomain(t *testing.T) {
+	provider, _ := newProvider(t)
+	if err := provider.Present("example.net", "token", "token.key"); err == nil {
+		t.Error("Present for a domain outside the account returned no error")
+	}
+}
+
+func TestDNSProvider_Timeout(t *testing.T) {
+	t.Setenv(EnvPropagationTimeout, "120")
+	t.Setenv(EnvPollingInterval, "5")
+
+	provider, err := NewDNSProviderConfig(&Config{LoginToken: "1,token", PropagationTimeout: 2 * time.Minute, PollingInterval: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeout, interval := provider.Timeout()
+	if timeout != 2*time.Minute || interval != 5*time.Second {
+		t.Errorf("Timeout() = %v, %v", timeout, interval)
+	}
+
+	config := NewDefaultConfig()
+	if config.PropagationTimeout != 2*time.Minute || config.PollingInterval != 5*time.Second || config.TTL != 600 {
+		t.Errorf("NewDefaultConfig() = %+v", config)
+	}
+}
+
+func TestNewDNSProvider_MissingCredentials(t *testing.T) {
+	t.Setenv(EnvAPIKey, "")
+	if _, err := NewDNSProvider(); err == nil {
+		t.Error("NewDNSProvider without credentials returned no error")
+	}
+}
@@ -0,0 +1,370 @@
+// Package libdns implements the libdns interfaces for dnspod, e.g. for Caddy:
+//
+//	provider := &libdns.Provider{LoginToken: "ID,Token"}
+//	records, err := provider.GetRecords(ctx, "example.com.")
+//
+// Records are managed on the default line. The apex NS records, managed by dnspod,
+// are returned by GetRecords but left untouched by SetRecords. SetRecords and
+// DeleteRecords are not atomic: on error, the changes made so far are kept.
+// The records they and AppendRecords return hold the dnspod.Record served,
+// with its ID, as their ProviderData.
+package libdns
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decker502/dnspod-go"
+	"github.com/libdns/libdns"
+)
+
+// Provider manages the records of dnspod domains through libdns.
+type Provider struct {
+	// LoginToken is the "ID,Token" API token.
+	LoginToken string `json:"login_token,omitempty"`
+
+	// Client, if set, is used instead of a client authenticated with LoginToken.
+	Client *dnspod.Client `json:"-"`
+
+	mu      sync.Mutex
+	client  *dnspod.Client
+	domains map[string]string // zone name to domain ID
+}
+
+var (
+	_ libdns.RecordGetter   = (*Provider)(nil)
+	_ libdns.RecordAppender = (*Provider)(nil)
+	_ libdns.RecordSetter   = (*Provider)(nil)
+	_ libdns.RecordDeleter  = (*Provider)(nil)
+	_ libdns.ZoneLister     = (*Provider)(nil)
+)
+
+func (p *Provider) getClient() *dnspod.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Client != nil {
+		return p.Client
+	}
+	if p.client == nil {
+		p.client = dnspod.NewClient(dnspod.CommonParams{LoginToken: p.LoginToken})
+	}
+	return p.client
+}
+
+// domainID resolves a zone to the ID of the dnspod domain, caching the IDs of the domains listed.
+func (p *Provider) domainID(ctx context.Context, zone string) (string, error) {
+	name := strings.ToLower(strings.TrimSuffix(zone, "."))
+
+	p.mu.Lock()
+	id, ok := p.domains[name]
+	p.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	domains, err := p.getClient().Domains.ListAllDomainsContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.domains == nil {
+		p.domains = map[string]string{}
+	}
+	for _, d := range domains {
+		p.domains[strings.ToLower(d.Name)] = d.ID
+	}
+	if id, ok := p.domains[name]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("dnspod: zone %s not found", zone)
+}
+
+// ListZones implements libdns.ZoneLister.
+func (p *Provider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	domains, err := p.getClient().Domains.ListAllDomainsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zones := make([]libdns.Zone, 0, len(domains))
+	for _, d := range domains {
+		zones = append(zones, libdns.Zone{Name: d.Name + "."})
+	}
+	return zones, nil
+}
+
+// GetRecords implements libdns.RecordGetter.
+func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	_, current, err := p.records(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]libdns.Record, 0, len(current))
+	for _, r := range current {
+		records = append(records, toLibdns(r))
+	}
+	return records, nil
+}
+
+// AppendRecords implements libdns.RecordAppender.
+func (p *Provider) AppendRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	domainID, err := p.domainID(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := make([]dnspod.RecordOperation, 0, len(recs))
+	for _, rec := range recs {
+		r, err := fromLibdns(rec, zone)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, dnspod.RecordOperation{Kind: dnspod.RecordCreate, DomainID: domainID, Record: r})
+	}
+	added, err := p.apply(ctx, ops)
+	return toServed(added), err
+}
+
+// SetRecords implements libdns.RecordSetter: for every name and type of recs,
+// the records of the zone on the default line become exactly the given ones.
+func (p *Provider) SetRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	domainID, current, err := p.records(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := make([]dnspod.Record, 0, len(recs))
+	rrsets := map[string]bool{}
+	for _, rec := range recs {
+		r, err := fromLibdns(rec, zone)
+		if err != nil {
+			return nil, err
+		}
+		desired = append(desired, r)
+		rrsets[rrset(r)] = true
+	}
+	var affected []dnspod.Record
+	for _, r := range current {
+		if rrsets[rrset(r)] && (r.Line == "" || r.Line == dnspod.DefaultLine) {
+			affected = append(affected, r)
+		}
+	}
+
+	ops := dnspod.DiffRecords(domainID, affected, desired, false)
+	changed, err := p.apply(ctx, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	// The records set are those left unchanged and those created or updated,
+	// which follow the deletes in ops.
+	replaced := map[string]bool{}
+	deletes := 0
+	for _, op := range ops {
+		replaced[op.RecordID] = true
+		if op.Kind == dnspod.RecordDelete {
+			deletes++
+		}
+	}
+	var set []dnspod.Record
+	for _, r := range affected {
+		if !replaced[r.ID] {
+			set = append(set, r)
+		}
+	}
+	return toServed(append(set, changed[deletes:]...)), nil
+}
+
+// DeleteRecords implements libdns.RecordDeleter. Empty types, TTLs and values match any record.
+func (p *Provider) DeleteRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	domainID, current, err := p.records(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []dnspod.RecordOperation
+	deleted := map[string]bool{}
+	for _, rec := range recs {
+		rr := rec.RR()
+		name := relativeName(rr.Name, zone)
+		for _, r := range current {
+			if deleted[r.ID] || !matches(r, name, rr) {
+				continue
+			}
+			deleted[r.ID] = true
+			ops = append(ops, dnspod.RecordOperation{Kind: dnspod.RecordDelete, DomainID: domainID, RecordID: r.ID, Record: r})
+		}
+	}
+	removed, err := p.apply(ctx, ops)
+	return toServed(removed), err
+}
+
+// records lists the records of a zone.
+func (p *Provider) records(ctx context.Context, zone string) (string, []dnspod.Record, error) {
+	domainID, err := p.domainID(ctx, zone)
+	if err != nil {
+		return "", nil, err
+	}
+	current, err := p.getClient().Domains.ListAllRecordsContext(ctx, domainID)
+	if err != nil {
+		return "", nil, err
+	}
+	return domainID, current, nil
+}
+
+// apply runs the operations in order, stopping at the first failure, and returns
+// the records created or updated, as served, and those deleted.
+func (p *Provider) apply(ctx context.Context, ops []dnspod.RecordOperation) ([]dnspod.Record, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	results, err := p.getClient().Domains.Bulk(ctx, ops, dnspod.BulkOptions{StopOnError: true})
+	var records []dnspod.Record
+	for _, result := range results {
+		if result.Err == nil {
+			records = append(records, served(result))
+		}
+	}
+	return records, err
+}
+
+// served returns the record of a successful operation: for creates and updates, the
+// record requested with the ID, name and value returned by dnspod, which leaves
+// the other attributes out of its response.
+func served(result dnspod.BulkResult) dnspod.Record {
+	r := result.Operation.Record
+	if result.Operation.Kind == dnspod.RecordDelete {
+		return r
+	}
+	r.ID = result.Record.ID
+	if result.Record.Name != "" {
+		r.Name = result.Record.Name
+	}
+	if result.Record.Value != "" {
+		r.Value = result.Record.Value
+	}
+	return r
+}
+
+// toServed converts dnspod records to libdns records holding them as ProviderData.
+func toServed(records []dnspod.Record) []libdns.Record {
+	var converted []libdns.Record
+	for _, r := range records {
+		converted = append(converted, withProviderData(toLibdns(r), r))
+	}
+	return converted
+}
+
+// withProviderData sets the ProviderData of a record parsed by toLibdns.
+func withProviderData(rec libdns.Record, data any) libdns.Record {
+	switch rec := rec.(type) {
+	case libdns.Address:
+		rec.ProviderData = data
+		return rec
+	case libdns.CAA:
+		rec.ProviderData = data
+		return rec
+	case libdns.CNAME:
+		rec.ProviderData = data
+		return rec
+	case libdns.MX:
+		rec.ProviderData = data
+		return rec
+	case libdns.NS:
+		rec.ProviderData = data
+		return rec
+	case libdns.SRV:
+		rec.ProviderData = data
+		return rec
+	case libdns.ServiceBinding:
+		rec.ProviderData = data
+		return rec
+	case libdns.TXT:
+		rec.ProviderData = data
+		return rec
+	}
+	return rec
+}
+
+func rrset(r dnspod.Record) string {
+	return strings.ToLower(r.Name) + "|" + strings.ToUpper(r.Type)
+}
+
+// matches reports whether a dnspod record matches a libdns record to delete.
+func matches(r dnspod.Record, name string, rr libdns.RR) bool {
+	if !strings.EqualFold(r.Name, name) {
+		return false
+	}
+	if rr.Type != "" && !strings.EqualFold(r.Type, rr.Type) {
+		return false
+	}
+	if rr.TTL != 0 && r.TTL != ttl(rr.TTL) {
+		return false
+	}
+	return rr.Data == "" || toLibdns(r).RR().Data == rr.Data
+}
+
+// relativeName returns the dnspod subdomain of a libdns name, "@" for the apex.
+func relativeName(name, zone string) string {
+	if strings.HasSuffix(name, ".") {
+		name = libdns.RelativeName(name, zone)
+	}
+	if name == "" {
+		return "@"
+	}
+	return name
+}
+
+// ttl converts a libdns TTL to seconds, "" leaving the default TTL of the domain.
+func ttl(d time.Duration) string {
+	if d < time.Second {
+		return ""
+	}
+	return strconv.Itoa(int(d / time.Second))
+}
+
+// toLibdns converts a dnspod record to the matching libdns type.
+func toLibdns(r dnspod.Record) libdns.Record {
+	seconds, _ := strconv.Atoi(r.TTL)
+	rr := libdns.RR{
+		Name: r.Name,
+		TTL:  time.Duration(seconds) * time.Second,
+		Type: strings.ToUpper(r.Type),
+		Data: r.Value,
+	}
+	if rr.Type == "MX" {
+		mx := r.MX
+		if mx == "" {
+			mx = "0"
+		}
+		rr.Data = mx + " " + r.Value
+	}
+	if parsed, err := rr.Parse(); err == nil {
+		return parsed
+	}
+	return rr
+}
+
+// fromLibdns converts a libdns record to a dnspod record on the default line.
+func fromLibdns(rec libdns.Record, zone string) (dnspod.Record, error) {
+	rr := rec.RR()
+	r := dnspod.Record{
+		Name:  relativeName(rr.Name, zone),
+		Type:  strings.ToUpper(rr.Type),
+		Line:  dnspod.DefaultLine,
+		TTL:   ttl(rr.TTL),
+		Value: rr.Data,
+	}
+	if r.Type == "MX" {
+		preference, target, ok := strings.Cut(rr.Data, " ")
+		if !ok {
+			return r, fmt.Errorf("dnspod: invalid MX record data %q", rr.Data)
+		}
+		r.MX, r.Value = preference, strings.TrimSpace(target)
+	}
+	return r, nil
+}
@@ -0,0 +1,254 @@
+package libdns
+
+import (
+	"context"
+	"net/netip"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/decker502/dnspod-go"
+	"github.com/decker502/dnspod-go/dnspodtest"
+	"github.com/libdns/libdns"
+)
+
+func newProvider(t *testing.T, records ...dnspod.Record) (*Provider, *dnspodtest.Server, string) {
+	t.Helper()
+	srv := dnspodtest.NewServer()
+	t.Cleanup(srv.Close)
+	id := srv.AddDomain("example.com", records...)
+	return &Provider{Client: srv.Client()}, srv, id
+}
+
+// zone returns the records of the fake server in zone file form, apex NS records excluded.
+func zone(srv *dnspodtest.Server, id string) []string {
+	var lines []string
+	for _, r := range srv.Records(id) {
+		if r.Name == "@" && r.Type == "NS" {
+			continue
+		}
+		rr := toLibdns(r).RR()
+		lines = append(lines, rr.Name+" "+ttl(rr.TTL)+" "+rr.Type+" "+rr.Data)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func testZone(t *testing.T, srv *dnspodtest.Server, id string, want ...string) {
+	t.Helper()
+	sort.Strings(want)
+	if got := zone(srv, id); !reflect.DeepEqual(got, want) {
+		t.Errorf("zone = %q, want %q", got, want)
+	}
+}
+
+func TestProvider_GetRecords(t *testing.T) {
+	p, _, _ := newProvider(t,
+		dnspod.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: "600"},
+		dnspod.Record{Name: "@", Type: "MX", Value: "mail.example.com.", MX: "10", TTL: "3600"},
+		dnspod.Record{Name: "_acme-challenge", Type: "TXT", Value: "token", TTL: "600"},
+	)
+
+	records, err := p.GetRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatalf("GetRecords returned error: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("GetRecords returned %d records, want 5 including the apex NS records", len(records))
+	}
+
+	want := []libdns.Record{
+		libdns.Address{Name: "www", TTL: 10 * time.Minute, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.MX{Name: "@", TTL: time.Hour, Preference: 10, Target: "mail.example.com."},
+		libdns.TXT{Name: "_acme-challenge", TTL: 10 * time.Minute, Text: "token"},
+	}
+	if !reflect.DeepEqual(records[2:], want) {
+		t.Errorf("GetRecords returned %#v, want %#v", records[2:], want)
+	}
+}
+
+func TestProvider_UnknownZone(t *testing.T) {
+	p, _, _ := newProvider(t)
+
+	if _, err := p.GetRecords(context.Background(), "example.net."); err == nil {
+		t.Error("GetRecords of an unknown zone returned no error")
+	}
+}
+
+func TestProvider_AppendRecords(t *testing.T) {
+	p, srv, id := newProvider(t, dnspod.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: "600"})
+
+	added, err := p.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", TTL: 5 * time.Minute, IP: netip.MustParseAddr("192.0.2.2")},
+		libdns.TXT{Name: "_acme-challenge.example.com.", Text: "token"},
+		libdns.MX{Name: "@", Preference: 5, Target: "mx.example.com."},
+	})
+	if err != nil {
+		t.Fatalf("AppendRecords returned error: %v", err)
+	}
+	if len(added) != 3 {
+		t.Errorf("AppendRecords returned %d records, want 3", len(added))
+	}
+	for _, rec := range added {
+		if r, ok := rec.(libdns.TXT); ok && r.ProviderData.(dnspod.Record).ID == "" {
+			t.Errorf("AppendRecords returned %#v without its record ID", r)
+		}
+	}
+	testZone(t, srv, id,
+		"www 600 A 192.0.2.1",
+		"www 300 A 192.0.2.2",
+		"_acme-challenge 600 TXT token",
+		"@ 600 MX 5 mx.example.com.",
+	)
+
+	if _, err := p.AppendRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", IP: netip.MustParseAddr("192.0.2.1")},
+	}); err == nil {
+		t.Error("AppendRecords of an existing record returned no error")
+	}
+}
+
+func TestProvider_SetRecords(t *testing.T) {
+	p, srv, id := newProvider(t,
+		dnspod.Record{Name: "@", Type: "A", Value: "192.0.2.1", TTL: "3600"},
+		dnspod.Record{Name: "@", Type: "A", Value: "192.0.2.2", TTL: "3600"},
+		dnspod.Record{Name: "@", Type: "TXT", Value: "hello world", TTL: "3600"},
+		dnspod.Record{Name: "alpha", Type: "AAAA", Value: "2001:db8::1", TTL: "3600"},
+		dnspod.Record{Name: "alpha", Type: "AAAA", Value: "2001:db8::2", TTL: "3600"},
+		dnspod.Record{Name: "beta", Type: "AAAA", Value: "2001:db8::3", TTL: "3600"},
+	)
+
+	set, err := p.SetRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "@", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.3")},
+		libdns.Address{Name: "alpha", TTL: time.Hour, IP: netip.MustParseAddr("2001:db8::1")},
+		libdns.Address{Name: "alpha", TTL: time.Hour, IP: netip.MustParseAddr("2001:db8::2")},
+		libdns.Address{Name: "alpha", TTL: 2 * time.Hour, IP: netip.MustParseAddr("2001:db8::5")},
+	})
+	if err != nil {
+		t.Fatalf("SetRecords returned error: %v", err)
+	}
+	if len(set) != 4 {
+		t.Errorf("SetRecords returned %d records, want 4", len(set))
+	}
+	testZone(t, srv, id,
+		"@ 3600 A 192.0.2.3",
+		"@ 3600 TXT hello world",
+		"alpha 3600 AAAA 2001:db8::1",
+		"alpha 3600 AAAA 2001:db8::2",
+		"alpha 7200 AAAA 2001:db8::5",
+		"beta 3600 AAAA 2001:db8::3",
+	)
+	if n := srv.Calls("Record.Modify"); n != 0 {
+		t.Errorf("SetRecords modified %d unchanged records", n)
+	}
+
+	// A TTL change updates the record in place.
+	if _, err := p.SetRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "@", TTL: 10 * time.Minute, IP: netip.MustParseAddr("192.0.2.3")},
+	}); err != nil {
+		t.Fatalf("SetRecords returned error: %v", err)
+	}
+	if n := srv.Calls("Record.Modify"); n != 1 {
+		t.Errorf("SetRecords modified %d records, want 1", n)
+	}
+}
+
+func TestProvider_SetRecords_lines(t *testing.T) {
+	p, srv, id := newProvider(t,
+		dnspod.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: "600"},
+		dnspod.Record{Name: "www", Type: "A", Line: "电信", Value: "192.0.2.1", TTL: "600"},
+	)
+
+	set, err := p.SetRecords(context.Background(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", TTL: 10 * time.Minute, IP: netip.MustParseAddr("192.0.2.2")},
+	})
+	if err != nil {
+		t.Fatalf("SetRecords returned error: %v", err)
+	}
+	if len(set) != 1 || set[0].(libdns.Address).ProviderData.(dnspod.Record).ID == "" {
+		t.Errorf("SetRecords returned %#v, want the record created with its ID", set)
+	}
+
+	var lines []string
+	for _, r := range srv.Records(id) {
+		if r.Name == "www" {
+			lines = append(lines, r.Line+" "+r.Value)
+		}
+	}
+	sort.Strings(lines)
+	if want := []string{"电信 192.0.2.1", "默认 192.0.2.2"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("www records = %q, want %q", lines, want)
+	}
+}
+
+func TestProvider_DeleteRecords(t *testing.T) {
+	p, srv, id := newProvider(t,
+		dnspod.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: "600"},
+		dnspod.Record{Name: "www", Type: "A", Value: "192.0.2.2", TTL: "600"},
+		dnspod.Record{Name: "_acme-challenge", Type: "TXT", Value: "a", TTL: "600"},
+		dnspod.Record{Name: "_acme-challenge", Type: "TXT", Value: "b", TTL: "600"},
+	)
+
+	deleted, err := p.DeleteRecords(context.Background(), "example.com", []libdns.Record{
+		libdns.Address{Name: "www", TTL: 10 * time.Minute, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "www", TTL: 5 * time.Minute, IP: netip.MustParseAddr("192.0.2.2")}, // TTL mismatch
+		libdns.RR{Name: "_acme-challenge", Type: "TXT"},                                         // any value
+		libdns.RR{Name: "missing", Type: "A", Data: "192.0.2.9"},
+	})
+	if err != nil {
+		t.Fatalf("DeleteRecords returned error: %v", err)
+	}
+	if len(deleted) != 3 {
+		t.Errorf("DeleteRecords returned %d records, want 3", len(deleted))
+	}
+	testZone(t, srv, id, "www 600 A 192.0.2.2")
+}
+
+func TestProvider_ListZones(t *testing.T) {
+	p, srv, _ := newProvider(t)
+	srv.AddDomain("example.net")
+	srv.PageLimit = 1
+
+	zones, err := p.ListZones(context.Background())
+	if err != nil {
+		t.Fatalf("ListZones returned error: %v", err)
+	}
+	want := []libdns.Zone{{Name: "example.com."}, {Name: "example.net."}}
+	if !reflect.DeepEqual(zones, want) {
+		t.Errorf("ListZones returned %v, want %v", zones, want)
+	}
+}
+
+func TestProvider_GetRecords_paginated(t *testing.T) {
+	p, srv, _ := newProvider(t)
+	id := srv.AddDomain("example.net", dnspod.Record{Name: "www", Type: "A", Line: dnspod.DefaultLine, Value: "192.0.2.1", TTL: "600"})
+	srv.PageLimit = 1
+
+	records, err := p.GetRecords(context.Background(), "example.net.")
+	if err != nil {
+		t.Fatalf("GetRecords returned error: %v", err)
+	}
+	if want := len(srv.Records(id)); len(records) != want {
+		t.Errorf("GetRecords returned %d records, want %d", len(records), want)
+	}
+}
+
+func TestProvider_ContextCanceled(t *testing.T) {
+	p, srv, _ := newProvider(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.AppendRecords(ctx, "example.com.", []libdns.Record{libdns.TXT{Name: "x", Text: "y"}}); err == nil {
+		t.Error("AppendRecords with a canceled context returned no error")
+	}
+	if n := srv.Calls("Record.Create"); n != 0 {
+		t.Errorf("AppendRecords with a canceled context made %d calls", n)
+	}
+	if _, err := p.GetRecords(ctx, "example.com."); err == nil {
+		t.Error("GetRecords with a canceled context returned no error")
+	}
+	if n := srv.Calls("Record.List"); n != 0 {
+		t.Errorf("GetRecords with a canceled context made %d calls", n)
+	}
+}
@@ -0,0 +1,329 @@
+package dnspod
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LineKind classifies record lines (线路).
+type LineKind int
+
+const (
+	LineOther LineKind = iota
+	LineDefault
+	LineISP
+	LineRegion
+	LineSearchEngine
+	LineCustom
+)
+
+func (k LineKind) String() string {
+	switch k {
+	case LineDefault:
+		return "default"
+	case LineISP:
+		return "isp"
+	case LineRegion:
+		return "region"
+	case LineSearchEngine:
+		return "search_engine"
+	case LineCustom:
+		return "custom"
+	}
+	return "other"
+}
+
+// DefaultLine is the name of the line every grade provides.
+const DefaultLine = "默认"
+
+// Built-in line names used to classify the lines returned by dnspod.
+var (
+	ispLines = []string{"电信", "联通", "移动", "教育网", "铁通", "鹏博士", "广电网", "华数", "长城宽带"}
+
+	regionLines = []string{
+		"国内", "国外", "境内", "境外",
+		"亚洲", "欧洲", "北美洲", "南美洲", "非洲", "大洋洲",
+		"北京", "天津", "河北", "山西", "内蒙古", "辽宁", "吉林", "黑龙江", "上海", "江苏",
+		"浙江", "安徽", "福建", "江西", "山东", "河南", "湖北", "湖南", "广东", "广西",
+		"海南", "重庆", "四川", "贵州", "云南", "西藏", "陕西", "甘肃", "青海", "宁夏",
+		"新疆", "香港", "澳门", "台湾",
+	}
+
+	searchEngineLines = []string{"搜索引擎", "百度", "谷歌", "有道", "必应", "搜狗", "奇虎", "搜搜", "雅虎"}
+)
+
+// Line is a record line of a LineCatalog.
+type Line struct {
+	Name string   `json:"name"`
+	ID   string   `json:"line_id"`
+	Kind LineKind `json:"kind"`
+
+	// Parent is the name of the line this one refines, e.g. "电信" for "北京电信".
+	Parent string `json:"parent,omitempty"`
+}
+
+// LineCatalog holds the lines available to a domain, in dnspod order.
+type LineCatalog struct {
+	Grade string
+
+	lines  []Line
+	byName map[string]int
+	byID   map[string]int
+}
+
+// NewLineCatalog builds a catalog from line names in dnspod order and their IDs.
+// Lines missing from ids get an empty ID, except DefaultLine which is "0".
+func NewLineCatalog(grade string, names []string, ids map[string]string) *LineCatalog {
+	c := &LineCatalog{Grade: grade, byName: map[string]int{}, byID: map[string]int{}}
+	for _, name := range names {
+		if _, ok := c.byName[name]; ok {
+			continue
+		}
+		id := ids[name]
+		if id == "" && name == DefaultLine {
+			id = "0"
+		}
+		c.byName[name] = len(c.lines)
+		if id != "" {
+			c.byID[id] = len(c.lines)
+		}
+		c.lines = append(c.lines, Line{Name: name, ID: id})
+	}
+	for i := range c.lines {
+		c.lines[i].Kind, c.lines[i].Parent = c.classify(c.lines[i].Name)
+	}
+	return c
+}
+
+// classify derives the kind and the parent of a line from its name.
+func (c *LineCatalog) classify(name string) (LineKind, string) {
+	if name == DefaultLine {
+		return LineDefault, ""
+	}
+	for _, n := range ispLines {
+		if name == n {
+			return LineISP, ""
+		}
+	}
+	for _, n := range regionLines {
+		if name == n {
+			return LineRegion, ""
+		}
+	}
+	for _, n := range searchEngineLines {
+		if name == n {
+			return LineSearchEngine, ""
+		}
+	}
+	// Regional ISP lines, e.g. "北京电信", refine the ISP line.
+	for _, n := range ispLines {
+		if strings.HasSuffix(name, n) {
+			return LineISP, c.parent(n)
+		}
+	}
+	for _, n := range regionLines {
+		if strings.HasPrefix(name, n) {
+			return LineRegion, c.parent(n)
+		}
+	}
+	return LineCustom, ""
+}
+
+// parent returns name if the catalog holds such a line.
+func (c *LineCatalog) parent(name string) string {
+	if _, ok := c.byName[name]; ok {
+		return name
+	}
+	return ""
+}
+
+// Lines returns the lines in dnspod order.
+func (c *LineCatalog) Lines() []Line {
+	return append([]Line(nil), c.lines...)
+}
+
+// Children returns the lines refining the named line.
+func (c *LineCatalog) Children(name string) []Line {
+	var children []Line
+	for _, l := range c.lines {
+		if l.Parent == name {
+			children = append(children, l)
+		}
+	}
+	return children
+}
+
+// ByName resolves a line by name.
+func (c *LineCatalog) ByName(name string) (Line, bool) {
+	i, ok := c.byName[name]
+	if !ok {
+		return Line{}, false
+	}
+	return c.lines[i], true
+}
+
+// ByID resolves a line by ID.
+func (c *LineCatalog) ByID(id string) (Line, bool) {
+	i, ok := c.byID[id]
+	if !ok {
+		return Line{}, false
+	}
+	return c.lines[i], true
+}
+
+// RecordLines returns the lines as RecordLine values, in dnspod order.
+func (c *LineCatalog) RecordLines() []RecordLine {
+	ret := make([]RecordLine, 0, len(c.lines))
+	for _, l := range c.lines {
+		ret = append(ret, RecordLine{Line: l.Name, LineID: l.ID})
+	}
+	return ret
+}
+
+// Validate checks the Line and LineID of a record against the catalog
+// and returns the record with both of them set.
+func (c *LineCatalog) Validate(record Record) (Record, error) {
+	switch {
+	case record.Line == "" && record.LineID == "":
+		return record, nil
+	case record.LineID == "":
+		l, ok := c.ByName(record.Line)
+		if !ok {
+			return record, fmt.Errorf("line %q is not available for grade %s", record.Line, c.Grade)
+		}
+		record.LineID = l.ID
+	case record.Line == "":
+		l, ok := c.ByID(record.LineID)
+		if !ok {
+			return record, fmt.Errorf("line ID %q is not available for grade %s", record.LineID, c.Grade)
+		}
+		record.Line = l.Name
+	default:
+		l, ok := c.ByID(record.LineID)
+		if !ok || l.Name != record.Line {
+			return record, fmt.Errorf("line %q does not match line ID %q", record.Line, record.LineID)
+		}
+	}
+	return record, nil
+}
+
+// lineCatalogs caches the catalogs of the domains validated by the service.
+type lineCatalogs struct {
+	mu       sync.Mutex
+	catalogs map[string]*LineCatalog
+}
+
+// LineCatalog fetches the lines available to a domain of the given grade.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#record-line
+func (s *DomainsService) LineCatalog(domainGrade string, domainID string) (*LineCatalog, *Response, error) {
+	return s.lineCatalog(context.Background(), domainGrade, domainID)
+}
+
+func (s *DomainsService) lineCatalog(ctx context.Context, domainGrade string, domainID string) (*LineCatalog, *Response, error) {
+	path := recordAction("Line")
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_grade", domainGrade)
+	payload.Set("domain_id", domainID)
+	lines := linesWrapper{}
+	res, err := s.client.postContext(ctx, path, payload, &lines)
+	if err != nil {
+		return nil, res, err
+	}
+
+	ids := make(map[string]string, len(lines.LineIDs))
+	for name, v := range lines.LineIDs {
+		switch id := v.(type) {
+		case string:
+			ids[name] = id
+		case float64:
+			ids[name] = strconv.FormatFloat(id, 'f', -1, 64)
+		}
+	}
+	names := lines.Lines
+	if len(names) == 0 {
+		// Without the ordered names, the lines are sorted by ID, the default line first.
+		for name := range lines.LineIDs {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if ids[names[i]] != ids[names[j]] {
+				return lessID(ids[names[i]], ids[names[j]])
+			}
+			return names[i] < names[j]
+		})
+	}
+	return NewLineCatalog(domainGrade, names, ids), res, nil
+}
+
+// lessID orders dnspod IDs numerically, those made of several numbers, as the line
+// ID "10=1", being compared number by number.
+func lessID(a, b string) bool {
+	notDigit := func(r rune) bool { return r < '0' || r > '9' }
+	as, bs := strings.FieldsFunc(a, notDigit), strings.FieldsFunc(b, notDigit)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, y := strings.TrimLeft(as[i], "0"), strings.TrimLeft(bs[i], "0")
+		if len(x) != len(y) {
+			return len(x) < len(y)
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	if len(as) != len(bs) {
+		return len(as) < len(bs)
+	}
+	return a < b
+}
+
+// domainLineCatalog returns the cached catalog of a domain, fetching its grade and lines if needed.
+func (s *DomainsService) domainLineCatalog(ctx context.Context, domainID string) (*LineCatalog, error) {
+	s.lines.mu.Lock()
+	catalog, ok := s.lines.catalogs[domainID]
+	s.lines.mu.Unlock()
+	if ok {
+		return catalog, nil
+	}
+
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	returnedDomain := domainWrapper{}
+	if _, err := s.client.postContext(ctx, domainAction("Info"), payload, &returnedDomain); err != nil {
+		return nil, err
+	}
+	catalog, _, err := s.lineCatalog(ctx, returnedDomain.Domain.Grade, domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lines.mu.Lock()
+	if s.lines.catalogs == nil {
+		s.lines.catalogs = map[string]*LineCatalog{}
+	}
+	s.lines.catalogs[domainID] = catalog
+	s.lines.mu.Unlock()
+	return catalog, nil
+}
+
+// InvalidateLineCatalog drops the cached catalog of a domain, e.g. after its grade or custom lines changed.
+func (s *DomainsService) InvalidateLineCatalog(domainID string) {
+	s.lines.mu.Lock()
+	delete(s.lines.catalogs, domainID)
+	s.lines.mu.Unlock()
+}
+
+// validateRecordLine checks the line of a record before create and update when Client.ValidateLines is set.
+func (s *DomainsService) validateRecordLine(ctx context.Context, domainID string, record Record) (Record, error) {
+	if !s.client.ValidateLines || (record.Line == "" && record.LineID == "") {
+		return record, nil
+	}
+	catalog, err := s.domainLineCatalog(ctx, domainID)
+	if err != nil {
+		return record, err
+	}
+	return catalog.Validate(record)
+}
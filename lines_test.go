@@ -0,0 +1,145 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const recordLineResponse = `{
+	"status": {"code": "1", "message": "Action completed successful"},
+	"line_ids": {
+		"默认": 0,
+		"国内": "7=0",
+		"国外": "3=0",
+		"电信": "10=0",
+		"联通": "10=1",
+		"北京电信": "10=0=1",
+		"百度": "90=0=1",
+		"办公网": "1024"
+	},
+	"lines": ["默认", "国内", "国外", "电信", "联通", "北京电信", "百度", "办公网"]
+}`
+
+func TestNewLineCatalog(t *testing.T) {
+	catalog := NewLineCatalog("DP_Free", []string{"默认", "电信", "北京电信", "国内", "办公网"},
+		map[string]string{"电信": "10=0", "北京电信": "10=0=1", "国内": "7=0", "办公网": "1024"})
+
+	want := []Line{
+		{Name: "默认", ID: "0", Kind: LineDefault},
+		{Name: "电信", ID: "10=0", Kind: LineISP},
+		{Name: "北京电信", ID: "10=0=1", Kind: LineISP, Parent: "电信"},
+		{Name: "国内", ID: "7=0", Kind: LineRegion},
+		{Name: "办公网", ID: "1024", Kind: LineCustom},
+	}
+	if !reflect.DeepEqual(catalog.Lines(), want) {
+		t.Errorf("Lines() = %+v, want %+v", catalog.Lines(), want)
+	}
+	if children := catalog.Children("电信"); len(children) != 1 || children[0].Name != "北京电信" {
+		t.Errorf("Children(电信) = %+v", children)
+	}
+	if l, ok := catalog.ByID("7=0"); !ok || l.Name != "国内" {
+		t.Errorf("ByID(7=0) = %+v, %v", l, ok)
+	}
+}
+
+func TestLineCatalog_Validate(t *testing.T) {
+	catalog := NewLineCatalog("DP_Free", []string{"默认", "电信"}, map[string]string{"电信": "10=0"})
+
+	record, err := catalog.Validate(Record{Line: "电信"})
+	if err != nil || record.LineID != "10=0" {
+		t.Errorf("Validate(Line) = %+v, %v", record, err)
+	}
+	record, err = catalog.Validate(Record{LineID: "0"})
+	if err != nil || record.Line != DefaultLine {
+		t.Errorf("Validate(LineID) = %+v, %v", record, err)
+	}
+	if _, err := catalog.Validate(Record{Line: "移动"}); err == nil {
+		t.Errorf("Validate accepted a line unavailable for the grade")
+	}
+	if _, err := catalog.Validate(Record{Line: "电信", LineID: "0"}); err == nil {
+		t.Errorf("Validate accepted mismatching line and line ID")
+	}
+}
+
+func TestDomainsService_GetRecordLine_ordered(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.Line", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, recordLineResponse)
+	})
+
+	for i := 0; i < 5; i++ {
+		lines, _, err := client.Domains.GetRecordLine("DP_Free", "1")
+		if err != nil {
+			t.Fatalf("Domains.GetRecordLine returned error: %v", err)
+		}
+		if lines[0] != (RecordLine{Line: "默认", LineID: "0"}) || lines[7].Line != "办公网" {
+			t.Fatalf("Domains.GetRecordLine returned %+v, not in dnspod order", lines)
+		}
+	}
+}
+
+func TestDomainsService_LineCatalog_unordered(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.Line", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"line_ids":{"联通":"10=1","默认":0,"电信":"10=0","国内":"7=0"}}`)
+	})
+
+	for i := 0; i < 5; i++ {
+		catalog, _, err := client.Domains.LineCatalog("DP_Free", "1")
+		if err != nil {
+			t.Fatalf("Domains.LineCatalog returned error: %v", err)
+		}
+		want := []RecordLine{{"默认", "0"}, {"国内", "7=0"}, {"电信", "10=0"}, {"联通", "10=1"}}
+		if got := catalog.RecordLines(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Domains.LineCatalog returned %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestLessID(t *testing.T) {
+	ordered := []string{"0", "7", "7=0", "10=0", "10=1", "10=12", "123"}
+	for i := range ordered {
+		for j := range ordered {
+			if got := lessID(ordered[i], ordered[j]); got != (i < j) {
+				t.Errorf("lessID(%q, %q) = %v, want %v", ordered[i], ordered[j], got, i < j)
+			}
+		}
+	}
+}
+
+func TestDomainsService_CreateRecord_ValidateLines(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.Info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"domain": {"id":1, "name":"example.com", "grade":"DP_Free"}}`)
+	})
+	lineCalls := 0
+	mux.HandleFunc("/Record.Line", func(w http.ResponseWriter, r *http.Request) {
+		lineCalls++
+		testString(t, "domain_grade", r.FormValue("domain_grade"), "DP_Free")
+		fmt.Fprint(w, recordLineResponse)
+	})
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		testString(t, "record_line_id", r.FormValue("record_line_id"), "10=1")
+		fmt.Fprint(w, `{"status": {"code":"1"},"record":{"id":"1", "name":"www"}}`)
+	})
+
+	client.ValidateLines = true
+
+	if _, _, err := client.Domains.CreateRecord("1", Record{Name: "www", Line: "联通"}); err != nil {
+		t.Errorf("Domains.CreateRecord returned error: %v", err)
+	}
+	if _, _, err := client.Domains.CreateRecord("1", Record{Name: "www", Line: "移动"}); err == nil {
+		t.Errorf("Domains.CreateRecord accepted an unavailable line")
+	}
+	if lineCalls != 1 {
+		t.Errorf("Record.Line called %d times, want the catalog to be cached", lineCalls)
+	}
+}
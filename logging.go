@@ -0,0 +1,136 @@
+package dnspod
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// Logger is the interface used by the Client to report API calls.
+// It is satisfied by *slog.Logger.
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+// LogEntry describes a single API call once it has completed.
+// It is passed to Client.Hook and is the source of the Client.Logger records.
+type LogEntry struct {
+	Method     string
+	Action     string
+	Latency    time.Duration
+	StatusCode int    // HTTP status code, 0 if no response was received
+	Code       string // dnspod status code, if the body could be decoded
+	Message    string // dnspod status message
+	Payload    url.Values
+	Body       []byte
+	Err        error
+}
+
+// redactedValue replaces the value of every sensitive payload parameter.
+const redactedValue = "[REDACTED]"
+
+// sensitiveParams lists the payload parameters that must never reach a log.
+var sensitiveParams = []string{
+	"login_token",
+	"login_email",
+	"login_password",
+	"login_code",
+	"user_id",
+	"password",
+	"old_password",
+	"new_password",
+	"old_email",
+	"new_email",
+	"email",
+	"telephone",
+}
+
+// RedactPayload returns a copy of payload with every credential replaced by a placeholder.
+func RedactPayload(payload url.Values) url.Values {
+	redacted := make(url.Values, len(payload))
+	for k, v := range payload {
+		redacted[k] = append([]string(nil), v...)
+	}
+	for _, k := range sensitiveParams {
+		if _, ok := redacted[k]; ok {
+			redacted[k] = []string{redactedValue}
+		}
+	}
+	return redacted
+}
+
+// newLogEntry describes a completed API call.
+func newLogEntry(req *Request, result *Result, err error, latency time.Duration) LogEntry {
+	entry := LogEntry{Method: req.Method, Action: req.Action, Latency: latency, Payload: req.Payload, Err: err}
+	if result != nil {
+		if result.Response != nil {
+			entry.StatusCode = result.Response.StatusCode
+		}
+		entry.Code = result.Status.Code
+		entry.Message = result.Status.Message
+		entry.Body = result.Body
+	}
+	return entry
+}
+
+// logging is the built-in middleware reporting every API call to the configured Hook and Logger.
+func (c *Client) logging(next Handler) Handler {
+	return HandlerFunc(func(req *Request) (*Result, error) {
+		if c.Logger == nil && c.Hook == nil {
+			return next.Handle(req)
+		}
+		start := time.Now()
+		result, err := next.Handle(req)
+		c.logRequest(req.Context, newLogEntry(req, result, err, time.Since(start)))
+		return result, err
+	})
+}
+
+// logRequest reports a completed API call to the configured Hook and Logger.
+// Bodies are only included when LogBodies is set, and the payload is always redacted.
+func (c *Client) logRequest(ctx context.Context, entry LogEntry) {
+	if c.LogBodies {
+		entry.Payload = RedactPayload(entry.Payload)
+	} else {
+		entry.Payload = nil
+		entry.Body = nil
+	}
+
+	if c.Hook != nil {
+		c.Hook(entry)
+	}
+	if c.Logger == nil {
+		return
+	}
+
+	level := slog.LevelDebug
+	args := []any{
+		slog.String("method", entry.Method),
+		slog.String("action", entry.Action),
+		slog.Duration("latency", entry.Latency),
+		slog.Int("status_code", entry.StatusCode),
+	}
+	if entry.Code != "" {
+		args = append(args, slog.String("code", entry.Code))
+		if entry.Code != "1" {
+			level = slog.LevelWarn
+			args = append(args, slog.String("message", entry.Message))
+		}
+	}
+	if entry.Payload != nil {
+		args = append(args, slog.String("payload", entry.Payload.Encode()))
+	}
+	if entry.Body != nil {
+		args = append(args, slog.String("body", string(entry.Body)))
+	}
+	if entry.Err != nil {
+		var apiErr *APIError
+		if !errors.As(entry.Err, &apiErr) {
+			level = slog.LevelError
+		}
+		args = append(args, slog.Any("error", entry.Err))
+	}
+	c.Logger.Log(ctx, level, "dnspod request", args...)
+}
@@ -0,0 +1,74 @@
+package dnspod
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRedactPayload(t *testing.T) {
+	payload := url.Values{}
+	payload.Set("login_token", "13490,6b5976c68aba5b14a0558b77c17c3932")
+	payload.Set("user_id", "42")
+	payload.Set("domain_id", "1")
+
+	redacted := RedactPayload(payload)
+
+	testString(t, "RedactPayload login_token", redacted.Get("login_token"), redactedValue)
+	testString(t, "RedactPayload user_id", redacted.Get("user_id"), redactedValue)
+	testString(t, "RedactPayload domain_id", redacted.Get("domain_id"), "1")
+	testString(t, "RedactPayload original", payload.Get("login_token"), "13490,6b5976c68aba5b14a0558b77c17c3932")
+}
+
+func TestClient_Hook(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.Status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"6","message":"Domain id invalid"}}`)
+	})
+
+	var entries []LogEntry
+	client.Hook = func(entry LogEntry) { entries = append(entries, entry) }
+	client.LogBodies = true
+
+	client.Domains.UpdateStatus("1", "enable")
+
+	if len(entries) != 1 {
+		t.Fatalf("Hook called %d times, want 1", len(entries))
+	}
+	entry := entries[0]
+	testString(t, "LogEntry.Action", entry.Action, "Domain.Status")
+	testString(t, "LogEntry.Code", entry.Code, "6")
+	testString(t, "LogEntry.Message", entry.Message, "Domain id invalid")
+	testString(t, "LogEntry.Payload login_token", entry.Payload.Get("login_token"), redactedValue)
+	if entry.StatusCode != http.StatusOK {
+		t.Errorf("LogEntry.StatusCode = %d, want %d", entry.StatusCode, http.StatusOK)
+	}
+}
+
+func TestClient_Logger(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.Status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":""}}`)
+	})
+
+	var buf bytes.Buffer
+	client.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client.Domains.UpdateStatus("1", "enable")
+
+	out := buf.String()
+	if !strings.Contains(out, "action=Domain.Status") {
+		t.Errorf("Logger output %q does not contain the action", out)
+	}
+	if strings.Contains(out, "dnspod login token") || strings.Contains(out, "body=") {
+		t.Errorf("Logger output %q contains the payload or body while LogBodies is unset", out)
+	}
+}
@@ -0,0 +1,238 @@
+package dnspod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrDomainNotFound is returned by Manager.Locate when no account holds a domain.
+var ErrDomainNotFound = errors.New("dnspod: no account holds the domain")
+
+// Manager holds the clients of several named accounts, e.g.
+//
+//	m := dnspod.NewManager()
+//	m.Add("prod", dnspod.CommonParams{LoginToken: prodToken})
+//	m.Add("staging", dnspod.CommonParams{LoginToken: stagingToken})
+//	account, domain, err := m.Locate(ctx, "www.example.com")
+//
+// A Manager is safe for concurrent use.
+type Manager struct {
+	mu       sync.RWMutex
+	accounts map[string]*Client
+}
+
+// NewManager returns a Manager without accounts.
+func NewManager() *Manager {
+	return &Manager{accounts: map[string]*Client{}}
+}
+
+// Add adds an account, or replaces the one of the same name, and returns its client.
+func (m *Manager) Add(name string, params CommonParams) *Client {
+	client := NewClient(params)
+	m.AddClient(name, client)
+	return client
+}
+
+// AddClient adds an account served by an existing client, or replaces the one of the same name.
+func (m *Manager) AddClient(name string, client *Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accounts[name] = client
+}
+
+// Remove removes an account.
+func (m *Manager) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.accounts, name)
+}
+
+// Client returns the client of an account.
+func (m *Manager) Client(name string) (*Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	client, ok := m.accounts[name]
+	return client, ok
+}
+
+// Accounts returns the names of the accounts, sorted.
+func (m *Manager) Accounts() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.accounts))
+	for name := range m.accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AccountsError is returned when a query failed for some accounts.
+type AccountsError struct {
+	Errors map[string]error // by account
+}
+
+// Error implements the error interface.
+func (e *AccountsError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msgs := make([]string, 0, len(names))
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+	return "dnspod: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the accounts.
+func (e *AccountsError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ForEach calls fn concurrently for every account, and returns an *AccountsError holding
+// the errors fn returned, if any. Accounts not queried yet when ctx is done fail with its error,
+// and fn should bind its requests to ctx, e.g. with Client.DoContext, for the others to stop.
+func (m *Manager) ForEach(ctx context.Context, fn func(ctx context.Context, account string, client *Client) error) error {
+	m.mu.RLock()
+	accounts := make(map[string]*Client, len(m.accounts))
+	for name, client := range m.accounts {
+		accounts[name] = client
+	}
+	m.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = map[string]error{}
+	)
+	for name, client := range accounts {
+		wg.Add(1)
+		go func(name string, client *Client) {
+			defer wg.Done()
+			err := ctx.Err()
+			if err == nil {
+				err = fn(ctx, name, client)
+			}
+			if err != nil {
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+		}(name, client)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return &AccountsError{Errors: errs}
+	}
+	return nil
+}
+
+// zoneOf returns the most specific of domains holding name.
+func zoneOf(domains []Domain, name string) (Domain, bool) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	var zone Domain
+	for _, d := range domains {
+		n := strings.ToLower(d.Name)
+		if (name == n || strings.HasSuffix(name, "."+n)) && len(n) > len(zone.Name) {
+			zone = d
+		}
+	}
+	return zone, zone.ID != ""
+}
+
+// Locate returns the account holding the domain of fqdn, e.g. www.example.com, along with
+// that domain. When several accounts hold it, e.g. as a shared domain, or hold domains of fqdn,
+// the most specific domain wins, then the first account by name.
+//
+// The domains of every account are listed concurrently. The accounts failing are ignored when
+// another one holds the domain; otherwise, Locate returns their *AccountsError, or ErrDomainNotFound.
+func (m *Manager) Locate(ctx context.Context, fqdn string) (string, Domain, error) {
+	var (
+		mu      sync.Mutex
+		account string
+		zone    Domain
+	)
+	err := m.ForEach(ctx, func(ctx context.Context, name string, client *Client) error {
+		domains, err := client.Domains.listAllDomains(ctx)
+		if err != nil {
+			return err
+		}
+		d, ok := zoneOf(domains, fqdn)
+		if !ok {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(d.Name) > len(zone.Name) || (len(d.Name) == len(zone.Name) && name < account) {
+			account, zone = name, d
+		}
+		return nil
+	})
+	switch {
+	case account != "":
+		return account, zone, nil
+	case err != nil:
+		return "", Domain{}, err
+	}
+	return "", Domain{}, ErrDomainNotFound
+}
+
+// AccountRecord is a record found by Manager.FindRecords.
+type AccountRecord struct {
+	Account string
+	Domain  Domain
+	Record  Record
+}
+
+// FindRecords returns the records named fqdn, e.g. www.example.com, across all the accounts,
+// sorted by account. recordType restricts them to a type unless empty. The records found
+// are returned along with the *AccountsError of the accounts that failed, if any.
+func (m *Manager) FindRecords(ctx context.Context, fqdn, recordType string) ([]AccountRecord, error) {
+	var (
+		mu    sync.Mutex
+		found []AccountRecord
+	)
+	err := m.ForEach(ctx, func(ctx context.Context, account string, client *Client) error {
+		domains, err := client.Domains.listAllDomains(ctx)
+		if err != nil {
+			return err
+		}
+		zone, ok := zoneOf(domains, fqdn)
+		if !ok {
+			return nil
+		}
+		sub := strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(strings.TrimSuffix(fqdn, ".")), strings.ToLower(zone.Name)), ".")
+		if sub == "" {
+			sub = "@"
+		}
+		records, _, err := client.Domains.listRecords(ctx, RecordQuery{DomainID: zone.ID, SubDomain: sub, PageSize: 3000})
+		if IsNoRecords(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, r := range records.List {
+			if strings.EqualFold(r.Name, sub) && (recordType == "" || strings.EqualFold(r.Type, recordType)) {
+				found = append(found, AccountRecord{Account: account, Domain: zone, Record: r})
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Account < found[j].Account })
+	return found, err
+}
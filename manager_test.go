@@ -0,0 +1,112 @@
+package dnspod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// managerSetup serves the accounts prod, holding example.com, staging, holding
+// staging.example.com, and broken, failing.
+func managerSetup() *Manager {
+	mux.HandleFunc("/Domain.List", func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("login_token") {
+		case "prod":
+			fmt.Fprint(w, `{"status": {"code":"1"},"info":{"domain_total":2},"domains":[{"id":1,"name":"example.com"},{"id":2,"name":"example.net"}]}`)
+		case "staging":
+			fmt.Fprint(w, `{"status": {"code":"1"},"info":{"domain_total":1},"domains":[{"id":3,"name":"staging.example.com"}]}`)
+		default:
+			fmt.Fprint(w, `{"status": {"code":"-1","message":"Login failed"}}`)
+		}
+	})
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("domain_id") + " " + r.FormValue("sub_domain") {
+		case "1 www":
+			fmt.Fprint(w, `{"status": {"code":"1"},"info":{"record_total":"2"},"records":[
+				{"id":"10","name":"www","type":"A","value":"1.1.1.1"},
+				{"id":"11","name":"www","type":"AAAA","value":"::1"}]}`)
+		case "3 www":
+			fmt.Fprint(w, `{"status": {"code":"1"},"info":{"record_total":"1"},"records":[{"id":"30","name":"www","type":"A","value":"3.3.3.3"}]}`)
+		default:
+			fmt.Fprint(w, `{"status": {"code":"10","message":"No records"}}`)
+		}
+	})
+
+	m := NewManager()
+	for _, name := range []string{"prod", "staging", "broken"} {
+		c := m.Add(name, CommonParams{LoginToken: name})
+		c.BaseURL = server.URL + "/"
+	}
+	return m
+}
+
+func TestManager_Locate(t *testing.T) {
+	setup()
+	defer teardown()
+	m := managerSetup()
+
+	if got := m.Accounts(); !reflect.DeepEqual(got, []string{"broken", "prod", "staging"}) {
+		t.Errorf("Manager.Accounts returned %v", got)
+	}
+
+	tests := []struct {
+		name, account, domainID string
+	}{
+		{"example.com", "prod", "1"},
+		{"www.example.net.", "prod", "2"},
+		{"www.staging.example.com", "staging", "3"},
+	}
+	for _, tt := range tests {
+		account, domain, err := m.Locate(context.Background(), tt.name)
+		if err != nil || account != tt.account || domain.ID != tt.domainID {
+			t.Errorf("Manager.Locate(%q) returned %s, %s, %v, want %s, %s", tt.name, account, domain.ID, err, tt.account, tt.domainID)
+		}
+	}
+
+	// The account failing may hold the domain.
+	_, _, err := m.Locate(context.Background(), "example.org")
+	var accountsErr *AccountsError
+	if !errors.As(err, &accountsErr) || len(accountsErr.Errors) != 1 || accountsErr.Errors["broken"] == nil {
+		t.Errorf("Manager.Locate returned %v, want an error for the broken account", err)
+	}
+
+	m.Remove("broken")
+	if _, _, err := m.Locate(context.Background(), "example.org"); err != ErrDomainNotFound {
+		t.Errorf("Manager.Locate returned %v, want ErrDomainNotFound", err)
+	}
+}
+
+func TestManager_FindRecords(t *testing.T) {
+	setup()
+	defer teardown()
+	m := managerSetup()
+	m.Remove("broken")
+
+	found, err := m.FindRecords(context.Background(), "www.example.com", "a")
+	if err != nil {
+		t.Fatalf("Manager.FindRecords returned error: %v", err)
+	}
+	if len(found) != 1 || found[0].Account != "prod" || found[0].Record.ID != "10" || found[0].Domain.Name != "example.com" {
+		t.Errorf("Manager.FindRecords returned %+v", found)
+	}
+
+	found, _ = m.FindRecords(context.Background(), "www.staging.example.com", "")
+	if len(found) != 1 || found[0].Account != "staging" || found[0].Record.ID != "30" {
+		t.Errorf("Manager.FindRecords returned %+v", found)
+	}
+
+	// Record.List fails with code 10 when the domain has no such record.
+	found, err = m.FindRecords(context.Background(), "mail.example.com", "")
+	if err != nil || len(found) != 0 {
+		t.Errorf("Manager.FindRecords returned %+v, %v, want no records", found, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.FindRecords(ctx, "www.example.com", ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("Manager.FindRecords returned %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,124 @@
+package dnspod
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Request is a dnspod API call travelling through the middleware chain.
+type Request struct {
+	Context context.Context
+	Method  string
+	Action  string
+	Payload url.Values
+}
+
+// Result is the outcome of a dnspod API call.
+// Middlewares may receive a non-nil Result alongside an error,
+// e.g. when dnspod answered with a status code different from "1".
+type Result struct {
+	Response *Response
+	Status   Status
+	Body     []byte
+}
+
+// Handler performs a dnspod API call.
+type Handler interface {
+	Handle(req *Request) (*Result, error)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as a Handler.
+type HandlerFunc func(req *Request) (*Result, error)
+
+// Handle calls f(req).
+func (f HandlerFunc) Handle(req *Request) (*Result, error) {
+	return f(req)
+}
+
+// Middleware wraps a Handler to add cross-cutting behavior to every API call.
+type Middleware func(next Handler) Handler
+
+// An APIError is returned when dnspod answers an action with a status code different from "1".
+type APIError struct {
+	Response *http.Response // HTTP response that caused this error
+	Action   string
+	Status   Status
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s %s", e.Action, e.Status.Code, e.Status.Message)
+}
+
+// CheckResponses is the default middleware, innermost in every Client chain.
+// It turns non-2xx HTTP responses into an *ErrorResponse using CheckResponse,
+// and dnspod status codes different from "1" into an *APIError.
+func CheckResponses(next Handler) Handler {
+	return HandlerFunc(func(req *Request) (*Result, error) {
+		result, err := next.Handle(req)
+		if err != nil {
+			return result, err
+		}
+
+		res := result.Response.Response
+		res.Body = io.NopCloser(bytes.NewReader(result.Body))
+		if err := CheckResponse(res); err != nil {
+			return result, err
+		}
+
+		if result.Status.Code != "" && result.Status.Code != "1" {
+			return result, &APIError{Response: res, Action: req.Action, Status: result.Status}
+		}
+		return result, nil
+	})
+}
+
+// Use appends middlewares to the client chain.
+// The first middleware added is the outermost one, closest to the caller.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.Middlewares = append(c.Middlewares, middlewares...)
+}
+
+// handler builds the chain every API call goes through: instrumentation and logging,
+// then the client Middlewares in order, then CheckResponses and finally the HTTP transport.
+func (c *Client) handler() Handler {
+	var h Handler = HandlerFunc(c.send)
+	h = CheckResponses(h)
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		h = c.Middlewares[i](h)
+	}
+	h = c.logging(h)
+	h = c.instrumentation(h)
+	return h
+}
+
+// send performs the HTTP request and reads the raw response body.
+func (c *Client) send(req *Request) (*Result, error) {
+	httpReq, err := c.NewRequest(req.Method, req.Action, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if req.Context != nil {
+		httpReq = httpReq.WithContext(req.Context)
+	}
+
+	res, err := c.HttpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	result := &Result{Response: &Response{Response: res}}
+
+	result.Body, err = io.ReadAll(res.Body)
+	if err != nil {
+		return result, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(result.Body))
+	result.Status, _ = decodeStatus(result.Body)
+
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package dnspod
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Use(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.Status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":""}}`)
+	})
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return HandlerFunc(func(req *Request) (*Result, error) {
+				calls = append(calls, name+" "+req.Action)
+				return next.Handle(req)
+			})
+		}
+	}
+	client.Use(record("outer"), record("inner"))
+
+	_, err := client.Domains.UpdateStatus("1", "enable")
+	if err != nil {
+		t.Errorf("Domains.UpdateStatus returned error: %v", err)
+	}
+
+	want := []string{"outer Domain.Status", "inner Domain.Status"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("middlewares called %v, want %v", calls, want)
+	}
+}
+
+func TestClient_Use_shortCircuit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.Use(func(next Handler) Handler {
+		return HandlerFunc(func(req *Request) (*Result, error) {
+			return &Result{Body: []byte(`{"status": {"code":"1"},"domain": {"id":1, "name":"example.com"}}`)}, nil
+		})
+	})
+
+	domain, _, err := client.Domains.Get(1)
+	if err != nil {
+		t.Errorf("Domains.Get returned error: %v", err)
+	}
+	testString(t, "Domains.Get", domain.Name, "example.com")
+}
+
+func TestCheckResponses_APIError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"10","message":"No records"}}`)
+	})
+
+	var seen *Result
+	client.Use(func(next Handler) Handler {
+		return HandlerFunc(func(req *Request) (*Result, error) {
+			result, err := next.Handle(req)
+			seen = result
+			return result, err
+		})
+	})
+
+	_, _, err := client.Domains.ListRecords(RecordQuery{DomainID: "1"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Domains.ListRecords returned %v, want an *APIError", err)
+	}
+	testString(t, "APIError.Action", apiErr.Action, "Record.List")
+	testString(t, "APIError.Status.Code", apiErr.Status.Code, "10")
+	if seen == nil || seen.Status.Code != "10" {
+		t.Errorf("middleware saw result %+v, want the decoded status", seen)
+	}
+}
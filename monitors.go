@@ -0,0 +1,350 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MonitorsService handles communication with the D-Monitor (health check) related
+// methods of the dnspod API.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/monitor.html
+type MonitorsService struct {
+	client *Client
+}
+
+// Monitor protocols.
+const (
+	MonitorHTTP  = "http"
+	MonitorHTTPS = "https"
+)
+
+// Switch-over modes applied when a monitored record goes down.
+const (
+	SwitchNotifyOnly = "pass_ip" // keep serving the record, only notify
+	SwitchPause      = "pause"   // pause the record
+)
+
+// SwitchPolicy is what dnspod does with a record once its monitor reports it down:
+// either one of the switch-over modes, or switching to backup IPs.
+type SwitchPolicy struct {
+	Mode      string   `json:"mode,omitempty"`
+	BackupIPs []string `json:"backup_ips,omitempty"`
+}
+
+// MonitorCallback is the URL called when the state of a monitor changes.
+type MonitorCallback struct {
+	URL string `json:"url,omitempty"`
+	Key string `json:"key,omitempty"`
+}
+
+// Monitor is a D-Monitor health check attached to a record.
+type Monitor struct {
+	ID        string `json:"monitor_id,omitempty"`
+	DomainID  string `json:"domain_id,omitempty"`
+	RecordID  string `json:"record_id,omitempty"`
+	SubDomain string `json:"sub_domain,omitempty"`
+	Line      string `json:"record_line,omitempty"`
+	IP        string `json:"ip,omitempty"`
+
+	Host     string   `json:"host,omitempty"`
+	Port     int      `json:"port,omitempty"`
+	Protocol string   `json:"monitor_type,omitempty"`
+	Path     string   `json:"monitor_path,omitempty"`
+	Interval int      `json:"monitor_interval,omitempty"` // seconds
+	Points   []string `json:"points,omitempty"`
+
+	SwitchPolicy SwitchPolicy    `json:"switch_policy,omitempty"`
+	Callback     MonitorCallback `json:"callback,omitempty"`
+	SMSNotice    string          `json:"sms_notice,omitempty"`
+	EmailNotice  string          `json:"email_notice,omitempty"`
+
+	Status       string `json:"status,omitempty"`
+	StatusCode   string `json:"status_code,omitempty"`
+	MonitorState string `json:"monitor_status,omitempty"` // "Ok" or "Down"
+}
+
+// MonitorDown is an entry of the downtime history.
+type MonitorDown struct {
+	MonitorID  string `json:"monitor_id"`
+	Domain     string `json:"domain"`
+	SubDomain  string `json:"sub_domain"`
+	Line       string `json:"record_line"`
+	IP         string `json:"ip"`
+	WarnReason string `json:"warn_reason"`
+	SwitchLog  string `json:"switch_log"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// MonitorDownsQuery pages through the downtime history.
+type MonitorDownsQuery struct {
+	CurrentPage int
+	PageSize    int
+}
+
+type PaginationMonitorDownList struct {
+	CurrentPage int           `json:"currentPage"`
+	PageSize    int           `json:"pageSize"`
+	Total       int           `json:"total"`
+	List        []MonitorDown `json:"list"`
+}
+
+// monitor is a monitor as returned by dnspod.
+type monitor struct {
+	ID           string `json:"monitor_id"`
+	DomainID     string `json:"domain_id"`
+	RecordID     string `json:"record_id"`
+	SubDomain    string `json:"sub_domain"`
+	Line         string `json:"record_line"`
+	IP           string `json:"ip"`
+	Host         string `json:"host"`
+	Port         int    `json:"port"`
+	Protocol     string `json:"monitor_type"`
+	Path         string `json:"monitor_path"`
+	Interval     int    `json:"monitor_interval"`
+	Points       string `json:"points"`
+	BackupIP     string `json:"bak_ip"`
+	CallbackURL  string `json:"callback_url"`
+	CallbackKey  string `json:"callback_key"`
+	SMSNotice    string `json:"sms_notice"`
+	EmailNotice  string `json:"email_notice"`
+	Status       string `json:"status"`
+	StatusCode   string `json:"status_code"`
+	MonitorState string `json:"monitor_status"`
+}
+
+type monitorsWrapper struct {
+	Status   Status    `json:"status"`
+	Monitors []monitor `json:"monitors"`
+}
+
+type monitorWrapper struct {
+	Status  Status   `json:"status"`
+	Monitor monitor  `json:"monitor"`
+	Info    *monitor `json:"info"`
+}
+
+type subdomainsWrapper struct {
+	Status     Status   `json:"status"`
+	Subdomains []string `json:"subdomain"`
+}
+
+type monitorDownsInfo struct {
+	Total int `json:"total"`
+}
+
+type monitorDownsWrapper struct {
+	Status Status           `json:"status"`
+	Info   monitorDownsInfo `json:"info"`
+	Downs  []MonitorDown    `json:"monitor_downs"`
+}
+
+// monitorAction generates the resource path for given monitor action.
+func monitorAction(action string) string {
+	if len(action) > 0 {
+		return fmt.Sprintf("Monitor.%s", action)
+	}
+	return "Monitor.List"
+}
+
+func init() {
+	registerReadOnly(monitorAction("Getdowns"), monitorAction("Listsubdomain"))
+}
+
+// splitList splits a comma separated list, ignoring empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Monitor returns the monitor as a Monitor.
+func (m monitor) Monitor() Monitor {
+	monitor := Monitor{
+		ID:           m.ID,
+		DomainID:     m.DomainID,
+		RecordID:     m.RecordID,
+		SubDomain:    m.SubDomain,
+		Line:         m.Line,
+		IP:           m.IP,
+		Host:         m.Host,
+		Port:         m.Port,
+		Protocol:     m.Protocol,
+		Path:         m.Path,
+		Interval:     m.Interval,
+		Points:       splitList(m.Points),
+		Callback:     MonitorCallback{URL: m.CallbackURL, Key: m.CallbackKey},
+		SMSNotice:    m.SMSNotice,
+		EmailNotice:  m.EmailNotice,
+		Status:       m.Status,
+		StatusCode:   m.StatusCode,
+		MonitorState: m.MonitorState,
+	}
+	switch m.BackupIP {
+	case SwitchNotifyOnly, SwitchPause, "":
+		monitor.SwitchPolicy.Mode = m.BackupIP
+	default:
+		monitor.SwitchPolicy.BackupIPs = splitList(m.BackupIP)
+	}
+	return monitor
+}
+
+// addMonitorAttributes sets the payload parameters describing a monitor.
+func addMonitorAttributes(payload url.Values, m Monitor) {
+	params := []struct{ key, value string }{
+		{"domain_id", m.DomainID},
+		{"record_id", m.RecordID},
+		{"host", m.Host},
+		{"monitor_type", m.Protocol},
+		{"monitor_path", m.Path},
+		{"points", strings.Join(m.Points, ",")},
+		{"callback_url", m.Callback.URL},
+		{"callback_key", m.Callback.Key},
+		{"sms_notice", m.SMSNotice},
+		{"email_notice", m.EmailNotice},
+	}
+	for _, p := range params {
+		if p.value != "" {
+			payload.Set(p.key, p.value)
+		}
+	}
+	if m.Port != 0 {
+		payload.Set("port", strconv.Itoa(m.Port))
+	}
+	if m.Interval != 0 {
+		payload.Set("monitor_interval", strconv.Itoa(m.Interval))
+	}
+	if len(m.SwitchPolicy.BackupIPs) > 0 {
+		payload.Set("bak_ip", strings.Join(m.SwitchPolicy.BackupIPs, ","))
+	} else if m.SwitchPolicy.Mode != "" {
+		payload.Set("bak_ip", m.SwitchPolicy.Mode)
+	}
+}
+
+// ListSubdomains lists the sub domains of a domain that can be monitored.
+func (s *MonitorsService) ListSubdomains(domainID string) ([]string, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+
+	wrapper := subdomainsWrapper{}
+	res, err := s.client.post(monitorAction("Listsubdomain"), payload, &wrapper)
+	if err != nil {
+		return nil, res, err
+	}
+	return wrapper.Subdomains, res, nil
+}
+
+// List the monitors of the account.
+func (s *MonitorsService) List() ([]Monitor, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+
+	wrapper := monitorsWrapper{}
+	res, err := s.client.post(monitorAction("List"), payload, &wrapper)
+	if err != nil {
+		return nil, res, err
+	}
+
+	monitors := make([]Monitor, 0, len(wrapper.Monitors))
+	for _, m := range wrapper.Monitors {
+		monitors = append(monitors, m.Monitor())
+	}
+	return monitors, res, nil
+}
+
+// Create a monitor for the record identified by the DomainID and RecordID of m.
+func (s *MonitorsService) Create(m Monitor) (Monitor, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	addMonitorAttributes(payload, m)
+
+	wrapper := monitorWrapper{}
+	res, err := s.client.post(monitorAction("Create"), payload, &wrapper)
+	if err != nil {
+		return Monitor{}, res, err
+	}
+	if wrapper.Monitor.ID != "" {
+		m.ID = wrapper.Monitor.ID
+	}
+	return m, res, nil
+}
+
+// Get fetches a monitor.
+func (s *MonitorsService) Get(monitorID string) (Monitor, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("monitor_id", monitorID)
+
+	wrapper := monitorWrapper{}
+	res, err := s.client.post(monitorAction("Info"), payload, &wrapper)
+	if err != nil {
+		return Monitor{}, res, err
+	}
+	if wrapper.Info != nil {
+		return wrapper.Info.Monitor(), res, nil
+	}
+	return wrapper.Monitor.Monitor(), res, nil
+}
+
+// Update a monitor.
+func (s *MonitorsService) Update(m Monitor) (Monitor, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("monitor_id", m.ID)
+	addMonitorAttributes(payload, m)
+
+	res, err := s.client.post(monitorAction("Modify"), payload, nil)
+	if err != nil {
+		return Monitor{}, res, err
+	}
+	return m, res, nil
+}
+
+// Delete a monitor.
+func (s *MonitorsService) Delete(monitorID string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("monitor_id", monitorID)
+
+	return s.client.post(monitorAction("Remove"), payload, nil)
+}
+
+// UpdateStatus enables or disables a monitor, status being "enabled" or "disabled".
+func (s *MonitorsService) UpdateStatus(monitorID string, status string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("monitor_id", monitorID)
+	payload.Set("status", status)
+
+	return s.client.post(monitorAction("Setstatus"), payload, nil)
+}
+
+// ListDowns pages through the downtime history of the account monitors.
+func (s *MonitorsService) ListDowns(query MonitorDownsQuery) (PaginationMonitorDownList, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	if query.PageSize != 0 {
+		payload.Set("offset", strconv.Itoa(query.CurrentPage))
+		payload.Set("length", strconv.Itoa(query.PageSize))
+	}
+
+	wrapper := monitorDownsWrapper{}
+	res, err := s.client.post(monitorAction("Getdowns"), payload, &wrapper)
+	if err != nil {
+		return PaginationMonitorDownList{}, res, err
+	}
+
+	downs := wrapper.Downs
+	if downs == nil {
+		downs = []MonitorDown{}
+	}
+	total := wrapper.Info.Total
+	if total == 0 {
+		total = len(downs)
+	}
+	return PaginationMonitorDownList{
+		CurrentPage: query.CurrentPage,
+		PageSize:    query.PageSize,
+		Total:       total,
+		List:        downs,
+	}, res, nil
+}
@@ -0,0 +1,138 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMonitorsService_Create(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Monitor.Create", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testFormValues(t, r, values{
+			"login_token":      "dnspod login token",
+			"domain_id":        "1",
+			"record_id":        "2",
+			"host":             "www.example.com",
+			"port":             "443",
+			"monitor_type":     MonitorHTTPS,
+			"monitor_path":     "/healthz",
+			"monitor_interval": "60",
+			"points":           "ctc,cuc",
+			"bak_ip":           "10.0.0.1,10.0.0.2",
+			"callback_url":     "https://hooks.example.com/dnspod",
+		})
+		fmt.Fprint(w, `{"status": {"code":"1"},"monitor":{"monitor_id":"0f4d3a5e-2a6c"}}`)
+	})
+
+	monitor, _, err := client.Monitors.Create(Monitor{
+		DomainID:     "1",
+		RecordID:     "2",
+		Host:         "www.example.com",
+		Port:         443,
+		Protocol:     MonitorHTTPS,
+		Path:         "/healthz",
+		Interval:     60,
+		Points:       []string{"ctc", "cuc"},
+		SwitchPolicy: SwitchPolicy{BackupIPs: []string{"10.0.0.1", "10.0.0.2"}},
+		Callback:     MonitorCallback{URL: "https://hooks.example.com/dnspod"},
+	})
+	if err != nil {
+		t.Fatalf("Monitors.Create returned error: %v", err)
+	}
+	testString(t, "Monitors.Create ID", monitor.ID, "0f4d3a5e-2a6c")
+}
+
+func TestMonitorsService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Monitor.Info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"info": {
+				"monitor_id": "0f4d3a5e-2a6c",
+				"domain_id": 1,
+				"record_id": "2",
+				"host": "www.example.com",
+				"port": 80,
+				"monitor_type": "http",
+				"monitor_path": "/",
+				"monitor_interval": "180",
+				"points": "ctc",
+				"bak_ip": "pause",
+				"status": "enabled",
+				"monitor_status": "Ok"
+			}}`)
+	})
+
+	monitor, _, err := client.Monitors.Get("0f4d3a5e-2a6c")
+	if err != nil {
+		t.Fatalf("Monitors.Get returned error: %v", err)
+	}
+
+	want := Monitor{
+		ID:           "0f4d3a5e-2a6c",
+		DomainID:     "1",
+		RecordID:     "2",
+		Host:         "www.example.com",
+		Port:         80,
+		Protocol:     MonitorHTTP,
+		Path:         "/",
+		Interval:     180,
+		Points:       []string{"ctc"},
+		SwitchPolicy: SwitchPolicy{Mode: SwitchPause},
+		Status:       "enabled",
+		MonitorState: "Ok",
+	}
+	if !reflect.DeepEqual(monitor, want) {
+		t.Errorf("Monitors.Get returned %+v, want %+v", monitor, want)
+	}
+}
+
+func TestMonitorsService_ListDowns(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Monitor.Getdowns", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "offset": "0", "length": "10"})
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"info": {"total": 1},
+			"monitor_downs": [{
+				"monitor_id": "0f4d3a5e-2a6c",
+				"domain": "example.com",
+				"sub_domain": "www",
+				"record_line": "默认",
+				"ip": "10.0.0.9",
+				"warn_reason": "连接超时",
+				"created_at": "2015-01-18 20:07:29"
+			}]}`)
+	})
+
+	downs, _, err := client.Monitors.ListDowns(MonitorDownsQuery{PageSize: 10})
+	if err != nil {
+		t.Fatalf("Monitors.ListDowns returned error: %v", err)
+	}
+	if downs.Total != 1 || len(downs.List) != 1 || downs.List[0].WarnReason != "连接超时" {
+		t.Errorf("Monitors.ListDowns returned %+v", downs)
+	}
+}
+
+func TestMonitorsService_UpdateStatus(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Monitor.Setstatus", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "monitor_id": "0f4d3a5e-2a6c", "status": "disabled"})
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+
+	if _, err := client.Monitors.UpdateStatus("0f4d3a5e-2a6c", "disabled"); err != nil {
+		t.Errorf("Monitors.UpdateStatus returned error: %v", err)
+	}
+}
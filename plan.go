@@ -0,0 +1,281 @@
+package dnspod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PlanAction is the kind of change of a PlanEntry.
+type PlanAction string
+
+const (
+	PlanCreate  PlanAction = "create"
+	PlanUpdate  PlanAction = "update"
+	PlanDelete  PlanAction = "delete"
+	PlanEnable  PlanAction = "enable"
+	PlanDisable PlanAction = "disable"
+)
+
+// PlanEntry is a single record change, with the record before and after it.
+type PlanEntry struct {
+	Action   PlanAction `json:"action"`
+	DomainID string     `json:"domain_id"`
+	RecordID string     `json:"record_id,omitempty"`
+	Before   *Record    `json:"before,omitempty"` // nil for create
+	After    *Record    `json:"after,omitempty"`  // nil for delete
+}
+
+// Plan is a reviewable list of record changes, e.g.
+//
+//	plan, err := client.Domains.Plan(ctx, "1", desired)
+//	...
+//	plan.WriteText(os.Stdout)
+//	err = client.Domains.ApplyPlan(ctx, plan)
+type Plan struct {
+	Entries []PlanEntry `json:"entries"`
+}
+
+// NewPlan returns the plan turning the current records of a domain into the desired ones,
+// the apex NS records being left out and the deletes coming first as by DiffRecords.
+func NewPlan(domainID string, current, desired []Record) *Plan {
+	byID := map[string]Record{}
+	for _, r := range current {
+		byID[r.ID] = r
+	}
+
+	plan := &Plan{Entries: []PlanEntry{}}
+	for _, op := range DiffRecords(domainID, current, desired, false) {
+		entry := PlanEntry{DomainID: domainID, RecordID: op.RecordID}
+		before, after := byID[op.RecordID], op.Record
+		switch op.Kind {
+		case RecordCreate:
+			entry.Action, entry.After = PlanCreate, &after
+		case RecordDelete:
+			entry.Action, entry.Before = PlanDelete, &before
+		case RecordUpdate:
+			entry.Action, entry.Before, entry.After = PlanUpdate, &before, &after
+			// A change of the enabled state alone is a status change.
+			unchanged := after
+			unchanged.Status = recordStatus(before)
+			if recordAttributesEqual(before, unchanged) {
+				entry.Action = PlanDisable
+				if RecordEnabled(after) {
+					entry.Action = PlanEnable
+				}
+			}
+		}
+		plan.Entries = append(plan.Entries, entry)
+	}
+	return plan
+}
+
+// Plan lists the records of a domain and returns the plan turning them into the desired ones,
+// its requests being bound to ctx.
+func (s *DomainsService) Plan(ctx context.Context, domainID string, desired []Record) (*Plan, error) {
+	current, err := s.listAllRecords(ctx, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if desired, err = s.resolveRecordLines(ctx, domainID, desired); err != nil {
+		return nil, err
+	}
+	return NewPlan(domainID, current, desired), nil
+}
+
+// Empty reports whether the plan has no change.
+func (p *Plan) Empty() bool {
+	return len(p.Entries) == 0
+}
+
+// Counts returns the number of entries of each action.
+func (p *Plan) Counts() map[PlanAction]int {
+	counts := map[PlanAction]int{}
+	for _, e := range p.Entries {
+		counts[e.Action]++
+	}
+	return counts
+}
+
+// formatRecord formats a record in zone file syntax, followed by its line and state.
+func formatRecord(r Record) string {
+	name := r.Name
+	if name == "" {
+		name = "@"
+	}
+	fields := []string{name}
+	if r.TTL != "" {
+		fields = append(fields, r.TTL)
+	}
+	fields = append(fields, "IN", r.Type)
+	if r.MX != "" && r.MX != "0" {
+		fields = append(fields, r.MX)
+	}
+	fields = append(fields, r.Value)
+
+	line := r.Line
+	if line == "" {
+		line = DefaultLine
+	}
+	comment := " ; " + line
+	if r.Weight != "" {
+		comment += ", weight " + r.Weight
+	}
+	if !RecordEnabled(r) {
+		comment += ", disabled"
+	}
+	return strings.Join(fields, " ") + comment
+}
+
+var planSymbols = map[PlanAction]string{
+	PlanCreate:  "+",
+	PlanUpdate:  "~",
+	PlanDelete:  "-",
+	PlanEnable:  "~",
+	PlanDisable: "~",
+}
+
+// WriteText writes the plan for humans, one change per line followed by a summary.
+func (p *Plan) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, e := range p.Entries {
+		switch e.Action {
+		case PlanCreate:
+			fmt.Fprintf(&b, "  %s %s\n", planSymbols[e.Action], formatRecord(*e.After))
+		case PlanDelete:
+			fmt.Fprintf(&b, "  %s %s\n", planSymbols[e.Action], formatRecord(*e.Before))
+		case PlanEnable, PlanDisable:
+			fmt.Fprintf(&b, "  %s %s (%s)\n", planSymbols[e.Action], formatRecord(*e.Before), e.Action)
+		case PlanUpdate:
+			fmt.Fprintf(&b, "  %s %s\n      => %s\n", planSymbols[e.Action], formatRecord(*e.Before), formatRecord(*e.After))
+		}
+	}
+	counts := p.Counts()
+	if p.Empty() {
+		b.WriteString("No changes.\n")
+	} else {
+		fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to delete, %d to enable, %d to disable.\n",
+			counts[PlanCreate], counts[PlanUpdate], counts[PlanDelete], counts[PlanEnable], counts[PlanDisable])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String returns the text rendering of the plan.
+func (p *Plan) String() string {
+	var b strings.Builder
+	p.WriteText(&b)
+	return b.String()
+}
+
+// WriteJSON writes the plan as JSON.
+func (p *Plan) WriteJSON(w io.Writer) error {
+	bs, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(bs, '\n'))
+	return err
+}
+
+// WriteDiff writes the plan as a unified diff of the live and desired records.
+func (p *Plan) WriteDiff(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("--- live\n+++ desired\n")
+	for _, e := range p.Entries {
+		fmt.Fprintf(&b, "@@ %s %s @@\n", e.Action, e.RecordID)
+		if e.Before != nil {
+			fmt.Fprintf(&b, "-%s\n", formatRecord(*e.Before))
+		}
+		switch {
+		case e.After != nil && e.Action != PlanEnable && e.Action != PlanDisable:
+			fmt.Fprintf(&b, "+%s\n", formatRecord(*e.After))
+		case e.Action == PlanEnable || e.Action == PlanDisable:
+			after := *e.Before
+			after.Enabled, after.Status = "", string(e.Action)
+			fmt.Fprintf(&b, "+%s\n", formatRecord(after))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// A PlanError is returned by ApplyPlan when an entry failed.
+type PlanError struct {
+	Entry       PlanEntry // entry that failed
+	Applied     int       // entries applied, then rolled back, before the failure
+	Err         error
+	RollbackErr error // first error rolling back the applied entries, if any
+}
+
+// Error implements the error interface.
+func (e *PlanError) Error() string {
+	msg := fmt.Sprintf("%s %s failed: %v", e.Entry.Action, e.Entry.RecordID, e.Err)
+	if e.RollbackErr != nil {
+		msg += fmt.Sprintf(" (rollback failed: %v)", e.RollbackErr)
+	}
+	return msg
+}
+
+// Unwrap returns the error of the failed entry.
+func (e *PlanError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyPlan applies the entries of a plan in order through a ChangeSet, their requests being
+// bound to ctx. When an entry fails, or the context is canceled, the entries already applied
+// are rolled back in reverse order and a *PlanError is returned. The rollback keeps the values
+// of ctx but not its cancellation, so that a canceled apply leaves the records as they were.
+// Records deleted then rolled back are recreated with new IDs.
+func (s *DomainsService) ApplyPlan(ctx context.Context, plan *Plan) error {
+	cs, err := s.NewChangeSet("")
+	if err != nil {
+		return err
+	}
+	for i, e := range plan.Entries {
+		err := ctx.Err()
+		if err != nil {
+			err = &ChangeSetError{Err: err, RollbackErr: cs.rollbackContext(context.WithoutCancel(ctx))}
+		} else {
+			err = cs.applyPlanEntry(ctx, e)
+		}
+		var csErr *ChangeSetError
+		if errors.As(err, &csErr) {
+			return &PlanError{Entry: e, Applied: i, Err: csErr.Err, RollbackErr: csErr.RollbackErr}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return cs.Commit()
+}
+
+// applyPlanEntry applies an entry, its Before record being what rolling it back restores.
+func (c *ChangeSet) applyPlanEntry(ctx context.Context, e PlanEntry) error {
+	switch e.Action {
+	case PlanCreate:
+		_, err := c.create(ctx, e.DomainID, *e.After)
+		return err
+	case PlanUpdate:
+		_, err := c.update(ctx, e.DomainID, e.RecordID, restorable(*e.Before), *e.After)
+		return err
+	case PlanDelete:
+		return c.delete(ctx, e.DomainID, e.RecordID, restorable(*e.Before))
+	case PlanEnable, PlanDisable:
+		return c.updateStatus(ctx, e.DomainID, e.RecordID, recordStatus(*e.Before), string(e.Action))
+	}
+	return fmt.Errorf("unknown plan action %q", e.Action)
+}
+
+// restorable returns the attributes recreating a live record.
+func restorable(r Record) Record {
+	r.ID = ""
+	r.Status = recordStatus(r)
+	r.Enabled = ""
+	r.MonitorStatus = ""
+	r.UpdateOn = ""
+	r.UseAQB = ""
+	return r
+}
@@ -0,0 +1,202 @@
+package dnspod
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var planCurrent = []Record{
+	{ID: "2", Name: "www", Type: "A", Line: "默认", Value: "1.1.1.1", TTL: "600", Enabled: "1"},
+	{ID: "3", Name: "old", Type: "A", Line: "默认", Value: "3.3.3.3", TTL: "600", Enabled: "1"},
+	{ID: "4", Name: "api", Type: "CNAME", Line: "默认", Value: "lb.example.net.", TTL: "600", Enabled: "0"},
+}
+
+var planDesired = []Record{
+	{Name: "www", Type: "A", Line: "默认", Value: "1.1.1.1", TTL: "300"},
+	{Name: "api", Type: "CNAME", Line: "默认", Value: "lb.example.net.", TTL: "600"},
+	{Name: "new", Type: "A", Line: "默认", Value: "4.4.4.4", TTL: "600"},
+}
+
+func TestNewPlan(t *testing.T) {
+	plan := NewPlan("1", planCurrent, planDesired)
+
+	var actions []string
+	for _, e := range plan.Entries {
+		actions = append(actions, string(e.Action)+" "+e.RecordID)
+	}
+	if got := strings.Join(actions, ", "); got != "delete 3, update 2, enable 4, create " {
+		t.Errorf("NewPlan actions = %s", got)
+	}
+	if plan.Entries[1].Before.TTL != "600" || plan.Entries[1].After.TTL != "300" {
+		t.Errorf("NewPlan update entry = %+v", plan.Entries[1])
+	}
+
+	if !NewPlan("1", planCurrent, planCurrent).Empty() {
+		t.Error("NewPlan of identical records is not empty")
+	}
+}
+
+func TestPlan_Render(t *testing.T) {
+	plan := NewPlan("1", planCurrent, planDesired)
+
+	text := plan.String()
+	for _, want := range []string{
+		"  ~ www 600 IN A 1.1.1.1 ; 默认\n      => www 300 IN A 1.1.1.1 ; 默认\n",
+		"  ~ api 600 IN CNAME lb.example.net. ; 默认, disabled (enable)\n",
+		"  + new 600 IN A 4.4.4.4 ; 默认\n",
+		"  - old 600 IN A 3.3.3.3 ; 默认\n",
+		"Plan: 1 to create, 1 to update, 1 to delete, 1 to enable, 0 to disable.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Plan text is missing %q:\n%s", want, text)
+		}
+	}
+
+	var diff bytes.Buffer
+	plan.WriteDiff(&diff)
+	if !strings.HasPrefix(diff.String(), "--- live\n+++ desired\n") ||
+		!strings.Contains(diff.String(), "-api 600 IN CNAME lb.example.net. ; 默认, disabled\n+api 600 IN CNAME lb.example.net. ; 默认\n") {
+		t.Errorf("Plan diff:\n%s", diff.String())
+	}
+
+	var js bytes.Buffer
+	plan.WriteJSON(&js)
+	if !strings.Contains(js.String(), `"action": "delete"`) || !strings.Contains(js.String(), `"before": {`) {
+		t.Errorf("Plan JSON:\n%s", js.String())
+	}
+}
+
+func TestDomainsService_ApplyPlan_rollback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "create "+r.FormValue("sub_domain"))
+		if r.FormValue("sub_domain") == "new" {
+			fmt.Fprint(w, `{"status": {"code":"104","message":"记录已经存在"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"status": {"code":"1"},"record":{"id":"10","name":"old"}}`)
+	})
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "remove "+r.FormValue("record_id"))
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+	mux.HandleFunc("/Record.Modify", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "modify "+r.FormValue("record_id")+" ttl "+r.FormValue("ttl"))
+		fmt.Fprint(w, `{"status": {"code":"1"},"record":{"id":"2"}}`)
+	})
+	mux.HandleFunc("/Record.Status", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "status "+r.FormValue("record_id")+" "+r.FormValue("status"))
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+
+	err := client.Domains.ApplyPlan(context.Background(), NewPlan("1", planCurrent, planDesired))
+
+	var planErr *PlanError
+	if !errors.As(err, &planErr) || planErr.Entry.Action != PlanCreate || planErr.Applied != 3 || planErr.RollbackErr != nil {
+		t.Fatalf("ApplyPlan returned %v, want a failed create after 3 entries", err)
+	}
+	want := "remove 3, modify 2 ttl 300, status 4 enable, create new, status 4 disable, modify 2 ttl 600, create old"
+	if got := strings.Join(calls, ", "); got != want {
+		t.Errorf("ApplyPlan calls = %s, want %s", got, want)
+	}
+}
+
+func TestDomainsService_ApplyPlan_swap(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cname := true
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		cname = false
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		if cname {
+			fmt.Fprint(w, `{"status": {"code":"104","message":"记录已经存在"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"status": {"code":"1"},"record":{"id":"3","name":"www"}}`)
+	})
+
+	current := []Record{{ID: "2", Name: "www", Type: "CNAME", Line: "默认", Value: "lb.example.net.", TTL: "600", Enabled: "1"}}
+	desired := []Record{{Name: "www", Type: "A", Line: "默认", Value: "1.1.1.1", TTL: "600"}}
+	plan := NewPlan("1", current, desired)
+
+	var actions []string
+	for _, e := range plan.Entries {
+		actions = append(actions, string(e.Action)+" "+e.RecordID)
+	}
+	if got := strings.Join(actions, ", "); got != "delete 2, create " {
+		t.Errorf("NewPlan actions = %s, want the delete first", got)
+	}
+	if err := client.Domains.ApplyPlan(context.Background(), plan); err != nil {
+		t.Errorf("ApplyPlan returned error: %v", err)
+	}
+}
+
+func TestDomainsService_ApplyPlan_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls []string
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "remove "+r.FormValue("record_id"))
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+	mux.HandleFunc("/Record.Modify", func(w http.ResponseWriter, r *http.Request) {
+		// The caller gives up while the update is in flight.
+		calls = append(calls, "modify "+r.FormValue("record_id"))
+		cancel()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "create "+r.FormValue("sub_domain"))
+		fmt.Fprint(w, `{"status": {"code":"1"},"record":{"id":"10","name":"old"}}`)
+	})
+
+	err := client.Domains.ApplyPlan(ctx, NewPlan("1", planCurrent, planDesired))
+
+	var planErr *PlanError
+	if !errors.As(err, &planErr) || !errors.Is(err, context.Canceled) || planErr.Applied != 1 || planErr.RollbackErr != nil {
+		t.Fatalf("ApplyPlan returned %v, want a canceled update after 1 entry", err)
+	}
+	want := "remove 3, modify 2, create old"
+	if got := strings.Join(calls, ", "); got != want {
+		t.Errorf("ApplyPlan calls = %s, want %s", got, want)
+	}
+}
+
+func TestDomainsService_Plan_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	listed := false
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		listed = true
+		fmt.Fprint(w, `{"status": {"code":"1"},"info": {"record_total": "0"},"records": []}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Domains.Plan(ctx, "1", planDesired); !errors.Is(err, context.Canceled) {
+		t.Errorf("Domains.Plan with a canceled context returned %v, want context.Canceled", err)
+	}
+	if listed {
+		t.Errorf("Domains.Plan with a canceled context listed the records")
+	}
+}
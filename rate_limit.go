@@ -0,0 +1,98 @@
+package dnspod
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter blocks until an API call may proceed.
+// It is satisfied by *rate.Limiter from golang.org/x/time/rate.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// RateLimit returns a middleware delaying every API call until limiter allows it.
+// Since every call made through a Client goes through its chain, concurrent callers
+// such as DomainsService.Bulk share the same limit.
+func RateLimit(limiter RateLimiter) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(req *Request) (*Result, error) {
+			ctx := req.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+			return next.Handle(req)
+		})
+	}
+}
+
+// TokenBucket is a RateLimiter allowing rate calls per second, with bursts of up to burst calls.
+// A rate of zero or less disables the limit.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a full TokenBucket.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait implements the RateLimiter interface.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay := b.reserve()
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available, or returns how long to wait for the next one.
+func (b *TokenBucket) reserve() time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return refillDelay(1-b.tokens, b.rate)
+}
+
+// refillDelay returns how long refilling missing tokens at rate takes, rounded up so that
+// a fraction of a token missing is never a zero delay, which would let Wait proceed.
+func refillDelay(missing, rate float64) time.Duration {
+	return time.Duration(math.Ceil(missing / rate * float64(time.Second)))
+}
@@ -0,0 +1,70 @@
+package dnspod
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Domain.Status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1","message":""}}`)
+	})
+
+	client.Use(RateLimit(NewTokenBucket(50, 1)))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.Domains.UpdateStatus("1", "enable"); err != nil {
+			t.Fatalf("Domains.UpdateStatus returned error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("3 calls at 50/s took %v, want at least 30ms", elapsed)
+	}
+}
+
+func TestRateLimit_nilContext(t *testing.T) {
+	calls := 0
+	handler := RateLimit(NewTokenBucket(1000, 1))(HandlerFunc(func(req *Request) (*Result, error) {
+		calls++
+		return &Result{}, nil
+	}))
+
+	// The second call waits for a token, on a background context.
+	for i := 0; i < 2; i++ {
+		if _, err := handler.Handle(&Request{Method: "POST", Action: "Domain.List"}); err != nil {
+			t.Fatalf("Handle returned error: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("the rate limited handler was called %d times, want 2", calls)
+	}
+}
+
+func TestTokenBucket_canceled(t *testing.T) {
+	bucket := NewTokenBucket(0.001, 1)
+	if err := bucket.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := bucket.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait returned %v, want context.Canceled", err)
+	}
+}
+
+func TestRefillDelay(t *testing.T) {
+	if d := refillDelay(1e-6, 1e6); d != time.Nanosecond {
+		t.Errorf("refillDelay of a fraction of a nanosecond = %v, want 1ns", d)
+	}
+	if d := refillDelay(0.5, 2); d != 250*time.Millisecond {
+		t.Errorf("refillDelay(0.5, 2) = %v, want 250ms", d)
+	}
+}
@@ -0,0 +1,231 @@
+package dnspod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ReconcileOptions configures DomainsService.Reconcile.
+type ReconcileOptions struct {
+	BulkOptions
+
+	// KeepExtra leaves the live records missing from the desired ones in place
+	// instead of deleting them.
+	KeepExtra bool
+}
+
+// recordKey identifies a record across its versions: records sharing a name, type,
+// line and value are the same record, anything else being an attribute.
+func recordKey(r Record) string {
+	return strings.ToLower(r.Name) + "|" + strings.ToUpper(r.Type) + "|" + recordLine(r) + "|" + recordValue(r)
+}
+
+// recordValue normalizes the value of a record for comparison: host names are
+// compared case-insensitively and regardless of their trailing dot.
+func recordValue(r Record) string {
+	switch strings.ToUpper(r.Type) {
+	case "CNAME", "MX", "NS", "PTR", "SRV":
+		return strings.TrimSuffix(strings.ToLower(r.Value), ".")
+	}
+	return r.Value
+}
+
+// recordLine returns the line of a record, DefaultLine when unset. A line given by
+// LineID alone must have been resolved by resolveRecordLines first.
+func recordLine(r Record) string {
+	if r.Line == "" && (r.LineID == "" || r.LineID == "0") {
+		return DefaultLine
+	}
+	return r.Line
+}
+
+// systemRecord reports whether a record is managed by dnspod itself, i.e. the apex NS records.
+func systemRecord(r Record) bool {
+	return r.Name == "@" && strings.ToUpper(r.Type) == "NS"
+}
+
+// RecordEnabled reports whether a record is enabled, dnspod reporting it either
+// through Enabled ("1" or "0") or Status ("enable", "enabled", "disable", ...).
+func RecordEnabled(r Record) bool {
+	switch {
+	case r.Enabled != "":
+		return r.Enabled == "1"
+	case r.Status != "":
+		return strings.HasPrefix(r.Status, "enable")
+	}
+	return true
+}
+
+// recordMX returns the MX priority of a record, "0" when unset.
+func recordMX(r Record) string {
+	if r.MX == "" {
+		return "0"
+	}
+	return r.MX
+}
+
+// recordStatus returns the status parameter matching RecordEnabled.
+func recordStatus(r Record) string {
+	if RecordEnabled(r) {
+		return "enable"
+	}
+	return "disable"
+}
+
+// recordAttributesEqual compares the attributes of a live record with the desired ones,
+// the attributes left empty in want being left to dnspod.
+func recordAttributesEqual(have, want Record) bool {
+	return (want.TTL == "" || have.TTL == want.TTL) &&
+		(want.MX == "" || recordMX(have) == recordMX(want)) &&
+		(want.Weight == "" || have.Weight == want.Weight) &&
+		(want.Remark == "" || have.Remark == want.Remark) &&
+		RecordEnabled(have) == RecordEnabled(want)
+}
+
+// DiffRecords returns the operations turning the current records of a domain into the desired ones.
+// The apex NS records, managed by dnspod, are left out. When keepExtra is set,
+// the current records missing from the desired ones are not deleted.
+//
+// The deletes come first, so that the records they remove no longer conflict with the
+// records created, e.g. when a CNAME is replaced by an A record of the same name.
+// Records are compared by line name, so the Line of those given a LineID alone must be
+// set first, as DomainsService.Reconcile, Plan and DriftReport do.
+func DiffRecords(domainID string, current, desired []Record, keepExtra bool) []RecordOperation {
+	live := map[string][]Record{}
+	for _, r := range current {
+		if systemRecord(r) {
+			continue
+		}
+		live[recordKey(r)] = append(live[recordKey(r)], r)
+	}
+
+	var ops, deletes []RecordOperation
+	for _, want := range desired {
+		if systemRecord(want) {
+			continue
+		}
+		want.ID = ""
+		want.Status = recordStatus(want)
+		want.Enabled = ""
+
+		key := recordKey(want)
+		matches := live[key]
+		if len(matches) == 0 {
+			ops = append(ops, RecordOperation{Kind: RecordCreate, DomainID: domainID, Record: want})
+			continue
+		}
+		have := matches[0]
+		live[key] = matches[1:]
+		if !recordAttributesEqual(have, want) {
+			ops = append(ops, RecordOperation{Kind: RecordUpdate, DomainID: domainID, RecordID: have.ID, Record: want})
+		}
+	}
+
+	if !keepExtra {
+		for _, r := range current {
+			if systemRecord(r) {
+				continue
+			}
+			for _, extra := range live[recordKey(r)] {
+				if extra.ID == r.ID {
+					deletes = append(deletes, RecordOperation{Kind: RecordDelete, DomainID: domainID, RecordID: r.ID})
+					break
+				}
+			}
+		}
+	}
+	return append(deletes, ops...)
+}
+
+// resolveRecordLines sets the Line of the records given a LineID alone, from the line
+// catalog of the domain, so that they compare with the live records by line name.
+func (s *DomainsService) resolveRecordLines(ctx context.Context, domainID string, records []Record) ([]Record, error) {
+	var catalog *LineCatalog
+	resolved := make([]Record, len(records))
+	for i, r := range records {
+		if r.Line == "" && r.LineID != "" && r.LineID != "0" {
+			if catalog == nil {
+				var err error
+				if catalog, err = s.domainLineCatalog(ctx, domainID); err != nil {
+					return nil, err
+				}
+			}
+			l, ok := catalog.ByID(r.LineID)
+			if !ok {
+				return nil, fmt.Errorf("line ID %q is not available for grade %s", r.LineID, catalog.Grade)
+			}
+			r.Line = l.Name
+		}
+		resolved[i] = r
+	}
+	return resolved, nil
+}
+
+// ListAllRecords lists every record of a domain, following the pagination.
+func (s *DomainsService) ListAllRecords(domainID string) ([]Record, error) {
+	return s.listAllRecords(context.Background(), domainID)
+}
+
+// ListAllRecordsContext is ListAllRecords with its requests bound to ctx.
+func (s *DomainsService) ListAllRecordsContext(ctx context.Context, domainID string) ([]Record, error) {
+	return s.listAllRecords(ctx, domainID)
+}
+
+func (s *DomainsService) listAllRecords(ctx context.Context, domainID string) ([]Record, error) {
+	const pageSize = 3000
+
+	var records []Record
+	for {
+		page, _, err := s.listRecords(ctx, RecordQuery{DomainID: domainID, CurrentPage: len(records), PageSize: pageSize})
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page.List...)
+		if len(page.List) == 0 || len(records) >= page.Total {
+			return records, nil
+		}
+	}
+}
+
+// Reconcile makes the records of a domain match the desired ones, creating,
+// updating and deleting records as needed through Bulk, the deletes completing first.
+func (s *DomainsService) Reconcile(ctx context.Context, domainID string, desired []Record, opts ReconcileOptions) ([]BulkResult, error) {
+	current, err := s.listAllRecords(ctx, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if desired, err = s.resolveRecordLines(ctx, domainID, desired); err != nil {
+		return nil, err
+	}
+	ops := DiffRecords(domainID, current, desired, opts.KeepExtra)
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return s.bulkDeletesFirst(ctx, ops, opts.BulkOptions)
+}
+
+// bulkDeletesFirst applies the operations of DiffRecords through Bulk, the deletes leading
+// them being completed before the others start, so that the records they remove no longer
+// conflict with the records created, e.g. a CNAME replaced by an A record.
+// With StopOnError, a failed delete skips the other operations.
+func (s *DomainsService) bulkDeletesFirst(ctx context.Context, ops []RecordOperation, opts BulkOptions) ([]BulkResult, error) {
+	n := 0
+	for n < len(ops) && ops[n].Kind == RecordDelete {
+		n++
+	}
+	if n == 0 || n == len(ops) {
+		return s.Bulk(ctx, ops, opts)
+	}
+
+	results, err := s.Bulk(ctx, ops[:n], opts)
+	if err != nil && opts.StopOnError {
+		for _, op := range ops[n:] {
+			results = append(results, BulkResult{Operation: op, Err: ErrSkipped})
+		}
+	} else {
+		rest, _ := s.Bulk(ctx, ops[n:], opts)
+		results = append(results, rest...)
+	}
+	return results, bulkResultsError(ctx, results)
+}
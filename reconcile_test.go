@@ -0,0 +1,199 @@
+package dnspod
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestDiffRecords(t *testing.T) {
+	current := []Record{
+		{ID: "1", Name: "@", Type: "NS", Line: "默认", Value: "f1g1ns1.dnspod.net.", TTL: "86400", Enabled: "1"},
+		{ID: "2", Name: "www", Type: "A", Line: "默认", Value: "1.1.1.1", TTL: "600", Enabled: "1"},
+		{ID: "3", Name: "www", Type: "A", Line: "默认", Value: "2.2.2.2", TTL: "600", Enabled: "1"},
+		{ID: "4", Name: "mail", Type: "MX", Line: "默认", Value: "mx.example.com.", MX: "10", TTL: "600", Enabled: "1"},
+	}
+	desired := []Record{
+		{Name: "www", Type: "A", Line: "默认", Value: "1.1.1.1", TTL: "600", Status: "enable"},
+		{Name: "mail", Type: "MX", Line: "默认", Value: "mx.example.com.", MX: "20", TTL: "600", Enabled: "1"},
+		{Name: "api", Type: "CNAME", Value: "www.example.com.", TTL: "600", Enabled: "0"},
+	}
+
+	ops := DiffRecords("1", current, desired, false)
+	if len(ops) != 3 {
+		t.Fatalf("DiffRecords returned %d operations, want 3: %+v", len(ops), ops)
+	}
+	if ops[0].Kind != RecordDelete || ops[0].RecordID != "3" {
+		t.Errorf("DiffRecords delete = %+v", ops[0])
+	}
+	if ops[1].Kind != RecordUpdate || ops[1].RecordID != "4" || ops[1].Record.MX != "20" {
+		t.Errorf("DiffRecords update = %+v", ops[1])
+	}
+	if ops[2].Kind != RecordCreate || ops[2].Record.Name != "api" || ops[2].Record.Status != "disable" {
+		t.Errorf("DiffRecords create = %+v", ops[2])
+	}
+
+	if ops := DiffRecords("1", current, desired, true); len(ops) != 2 {
+		t.Errorf("DiffRecords keeping extra records returned %d operations, want 2", len(ops))
+	}
+	if ops := DiffRecords("1", current, current, false); len(ops) != 0 {
+		t.Errorf("DiffRecords of identical records returned %+v", ops)
+	}
+}
+
+func TestDomainsService_Reconcile(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"info": {"record_total": "2"},
+			"records": [
+				{"id":"2","name":"www","line":"默认","type":"A","ttl":"600","value":"1.1.1.1","enabled":"1"},
+				{"id":"3","name":"old","line":"默认","type":"A","ttl":"600","value":"3.3.3.3","enabled":"1"}
+			]}`)
+	})
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{
+			"login_token": "dnspod login token",
+			"domain_id":   "1",
+			"sub_domain":  "new",
+			"record_type": "A",
+			"record_line": "默认",
+			"value":       "4.4.4.4",
+			"ttl":         "600",
+			"status":      "enable",
+		})
+		fmt.Fprint(w, `{"status": {"code":"1"},"record":{"id":"5","name":"new"}}`)
+	})
+	removed := ""
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		removed = r.FormValue("record_id")
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+
+	desired := []Record{
+		{Name: "www", Type: "A", Line: "默认", Value: "1.1.1.1", TTL: "600"},
+		{Name: "new", Type: "A", Line: "默认", Value: "4.4.4.4", TTL: "600"},
+	}
+	results, err := client.Domains.Reconcile(context.Background(), "1", desired, ReconcileOptions{})
+	if err != nil {
+		t.Fatalf("Domains.Reconcile returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Domains.Reconcile returned %d results, want 2", len(results))
+	}
+	testString(t, "Domains.Reconcile removed record", removed, "3")
+}
+
+func TestDomainsService_Reconcile_swap(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"info": {"record_total": "1"},
+			"records": [{"id":"2","name":"www","line":"默认","type":"CNAME","ttl":"600","value":"lb.example.net.","enabled":"1"}]}`)
+	})
+	var mu sync.Mutex
+	cname := true
+	mux.HandleFunc("/Record.Remove", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		cname = false
+		mu.Unlock()
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if cname {
+			fmt.Fprint(w, `{"status": {"code":"104","message":"记录已经存在"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"status": {"code":"1"},"record":{"id":"3","name":"www"}}`)
+	})
+
+	desired := []Record{{Name: "www", Type: "A", Line: "默认", Value: "1.1.1.1", TTL: "600"}}
+	opts := ReconcileOptions{BulkOptions: BulkOptions{Concurrency: 4}}
+	if _, err := client.Domains.Reconcile(context.Background(), "1", desired, opts); err != nil {
+		t.Fatalf("Domains.Reconcile returned error: %v", err)
+	}
+}
+
+func TestDomainsService_Reconcile_lineID(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"info": {"record_total": "1"},
+			"records": [{"id":"2","name":"www","line":"电信","line_id":"10=0","type":"A","ttl":"600","value":"1.1.1.1","enabled":"1"}]}`)
+	})
+	mux.HandleFunc("/Domain.Info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"domain": {"id":1, "name":"example.com", "grade":"DP_Free"}}`)
+	})
+	mux.HandleFunc("/Record.Line", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, recordLineResponse)
+	})
+
+	desired := []Record{{Name: "www", Type: "A", LineID: "10=0", Value: "1.1.1.1", TTL: "600"}}
+	results, err := client.Domains.Reconcile(context.Background(), "1", desired, ReconcileOptions{})
+	if err != nil {
+		t.Fatalf("Domains.Reconcile returned error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Domains.Reconcile of a record on its line by ID returned %+v, want no change", results)
+	}
+}
+
+func TestDomainsService_Reconcile_trailingDot(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"info": {"record_total": "2"},
+			"records": [
+				{"id":"2","name":"www","line":"默认","type":"CNAME","ttl":"600","value":"lb.example.net","enabled":"1"},
+				{"id":"3","name":"@","line":"默认","type":"MX","ttl":"600","value":"mail.example.com","mx":"10","enabled":"1"}
+			]}`)
+	})
+
+	desired := []Record{
+		{Name: "www", Type: "CNAME", Line: "默认", Value: "LB.example.net.", TTL: "600"},
+		{Name: "@", Type: "MX", Line: "默认", Value: "mail.example.com.", MX: "10", TTL: "600"},
+	}
+	results, err := client.Domains.Reconcile(context.Background(), "1", desired, ReconcileOptions{})
+	if err != nil {
+		t.Fatalf("Domains.Reconcile returned error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Domains.Reconcile of absolute host names returned %+v, want no change", results)
+	}
+}
+
+func TestDomainsService_Reconcile_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	listed := false
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		listed = true
+		fmt.Fprint(w, `{"status": {"code":"1"},"info": {"record_total": "0"},"records": []}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Domains.Reconcile(ctx, "1", nil, ReconcileOptions{}); err == nil {
+		t.Errorf("Domains.Reconcile with a canceled context returned no error")
+	}
+	if listed {
+		t.Errorf("Domains.Reconcile with a canceled context listed the records")
+	}
+}
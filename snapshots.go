@@ -0,0 +1,194 @@
+package dnspod
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SnapshotsService handles communication with the snapshot related
+// methods of the dnspod API. Snapshots are stored by dnspod, see ZoneSnapshot
+// for snapshots kept on the client side.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#snapshot-config
+type SnapshotsService struct {
+	client *Client
+}
+
+// Periods of the automatic snapshots of a domain.
+const (
+	SnapshotDisabled = "disable"
+	SnapshotHourly   = "hourly"
+	SnapshotDaily    = "daily"
+	SnapshotWeekly   = "weekly"
+)
+
+// Snapshot is a copy of the records of a domain stored by dnspod.
+type Snapshot struct {
+	ID          string `json:"id,omitempty"`
+	Domain      string `json:"domain,omitempty"`
+	CreatedOn   string `json:"created_on,omitempty"`
+	RecordCount string `json:"record_count,omitempty"`
+}
+
+type snapshotsWrapper struct {
+	Status    Status     `json:"status"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+type snapshotWrapper struct {
+	Status   Status   `json:"status"`
+	Snapshot Snapshot `json:"snapshot"`
+	Records  []Record `json:"records"`
+}
+
+// snapshotAction generates the resource path for given snapshot action.
+func snapshotAction(action string) string {
+	if len(action) > 0 {
+		return fmt.Sprintf("Snapshot.%s", action)
+	}
+	return "Snapshot.List"
+}
+
+// Configure sets the period of the automatic snapshots of a domain,
+// one of SnapshotDisabled, SnapshotHourly, SnapshotDaily and SnapshotWeekly.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#snapshot-config
+func (s *SnapshotsService) Configure(domainID, period string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("period", period)
+
+	return s.client.post(snapshotAction("Config"), payload, nil)
+}
+
+// List the snapshots of a domain.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#snapshot-list
+func (s *SnapshotsService) List(domainID string) ([]Snapshot, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+
+	wrapper := snapshotsWrapper{}
+	res, err := s.client.post(snapshotAction("List"), payload, &wrapper)
+	if err != nil {
+		return nil, res, err
+	}
+	return wrapper.Snapshots, res, nil
+}
+
+// Create takes a snapshot of a domain.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#snapshot-create
+func (s *SnapshotsService) Create(domainID string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+
+	return s.client.post(snapshotAction("Create"), payload, nil)
+}
+
+// Get fetches a snapshot along with the records it holds.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#snapshot-info
+func (s *SnapshotsService) Get(domainID, snapshotID string) (Snapshot, []Record, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("snapshot_id", snapshotID)
+
+	wrapper := snapshotWrapper{}
+	res, err := s.client.post(snapshotAction("Info"), payload, &wrapper)
+	if err != nil {
+		return Snapshot{}, nil, res, err
+	}
+	return wrapper.Snapshot, wrapper.Records, res, nil
+}
+
+// Delete a snapshot.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#snapshot-remove
+func (s *SnapshotsService) Delete(domainID, snapshotID string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("snapshot_id", snapshotID)
+
+	return s.client.post(snapshotAction("Remove"), payload, nil)
+}
+
+// Rollback restores the records of a domain from a snapshot.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/records.html#snapshot-rollback
+func (s *SnapshotsService) Rollback(domainID, snapshotID string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	payload.Set("snapshot_id", snapshotID)
+
+	return s.client.post(snapshotAction("Rollback"), payload, nil)
+}
+
+// ZoneSnapshotVersion is the version of the ZoneSnapshot format written by this package.
+const ZoneSnapshotVersion = 1
+
+// ZoneSnapshot is a client-side copy of the records of a domain, e.g.
+//
+//	snapshot, err := client.Domains.TakeSnapshot("1")
+//	...
+//	_, err = snapshot.WriteTo(file)
+//
+// and later
+//
+//	snapshot, err := dnspod.ReadZoneSnapshot(file)
+//	...
+//	_, err = client.Domains.RestoreSnapshot(ctx, "1", snapshot, dnspod.ReconcileOptions{})
+type ZoneSnapshot struct {
+	Version  int       `json:"version"`
+	DomainID string    `json:"domain_id"`
+	TakenAt  time.Time `json:"taken_at"`
+	Records  []Record  `json:"records"`
+}
+
+// TakeSnapshot captures the records of a domain.
+func (s *DomainsService) TakeSnapshot(domainID string) (*ZoneSnapshot, error) {
+	records, err := s.ListAllRecords(domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &ZoneSnapshot{Version: ZoneSnapshotVersion, DomainID: domainID, TakenAt: time.Now().UTC()}
+	for _, r := range records {
+		// Drop the attributes maintained by dnspod.
+		r.MonitorStatus = ""
+		r.UpdateOn = ""
+		r.UseAQB = ""
+		snapshot.Records = append(snapshot.Records, r)
+	}
+	return snapshot, nil
+}
+
+// RestoreSnapshot makes the records of a domain match a snapshot, which may have
+// been taken from another domain.
+func (s *DomainsService) RestoreSnapshot(ctx context.Context, domainID string, snapshot *ZoneSnapshot, opts ReconcileOptions) ([]BulkResult, error) {
+	return s.Reconcile(ctx, domainID, snapshot.Records, opts)
+}
+
+// WriteTo writes the snapshot as JSON.
+func (z *ZoneSnapshot) WriteTo(w io.Writer) (int64, error) {
+	bs, err := json.MarshalIndent(z, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(bs, '\n'))
+	return int64(n), err
+}
+
+// ReadZoneSnapshot reads a snapshot written by ZoneSnapshot.WriteTo.
+func ReadZoneSnapshot(r io.Reader) (*ZoneSnapshot, error) {
+	snapshot := &ZoneSnapshot{}
+	if err := json.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("invalid zone snapshot: %v", err)
+	}
+	if snapshot.Version < 1 || snapshot.Version > ZoneSnapshotVersion {
+		return nil, fmt.Errorf("unsupported zone snapshot version %d", snapshot.Version)
+	}
+	return snapshot, nil
+}
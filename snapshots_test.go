@@ -0,0 +1,107 @@
+package dnspod
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotsService_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Snapshot.List", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testFormValues(t, r, values{"login_token": "dnspod login token", "domain_id": "1"})
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"snapshots": [{"id": 12, "domain": "example.com", "created_on": "2015-01-18 20:07:29", "record_count": 3}]}`)
+	})
+
+	snapshots, _, err := client.Snapshots.List("1")
+	if err != nil {
+		t.Fatalf("Snapshots.List returned error: %v", err)
+	}
+	want := []Snapshot{{ID: "12", Domain: "example.com", CreatedOn: "2015-01-18 20:07:29", RecordCount: "3"}}
+	if !reflect.DeepEqual(snapshots, want) {
+		t.Errorf("Snapshots.List returned %+v, want %+v", snapshots, want)
+	}
+}
+
+func TestSnapshotsService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Snapshot.Info", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "domain_id": "1", "snapshot_id": "12"})
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"snapshot": {"id": "12", "domain": "example.com"},
+			"records": [{"id": 2, "name": "www", "type": "A", "value": "1.1.1.1"}]}`)
+	})
+
+	snapshot, records, _, err := client.Snapshots.Get("1", "12")
+	if err != nil {
+		t.Fatalf("Snapshots.Get returned error: %v", err)
+	}
+	testString(t, "Snapshots.Get ID", snapshot.ID, "12")
+	if len(records) != 1 || records[0].ID != "2" {
+		t.Errorf("Snapshots.Get returned records %+v", records)
+	}
+}
+
+func TestSnapshotsService_Rollback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Snapshot.Rollback", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "domain_id": "1", "snapshot_id": "12"})
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+
+	if _, err := client.Snapshots.Rollback("1", "12"); err != nil {
+		t.Errorf("Snapshots.Rollback returned error: %v", err)
+	}
+}
+
+func TestZoneSnapshot_RoundTrip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"status": {"code":"1"},
+			"info": {"record_total": "1"},
+			"records": [{"id":"2","name":"www","line":"默认","type":"A","ttl":"600","value":"1.1.1.1","enabled":"1","updated_on":"2015-01-18 20:07:29","monitor_status":""}]}`)
+	})
+
+	snapshot, err := client.Domains.TakeSnapshot("1")
+	if err != nil {
+		t.Fatalf("Domains.TakeSnapshot returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := snapshot.WriteTo(&buf); err != nil {
+		t.Fatalf("ZoneSnapshot.WriteTo returned error: %v", err)
+	}
+	if strings.Contains(buf.String(), "updated_on") {
+		t.Errorf("ZoneSnapshot.WriteTo wrote volatile attributes: %s", buf.String())
+	}
+
+	read, err := ReadZoneSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadZoneSnapshot returned error: %v", err)
+	}
+	if !reflect.DeepEqual(read, snapshot) {
+		t.Errorf("ReadZoneSnapshot returned %+v, want %+v", read, snapshot)
+	}
+}
+
+func TestReadZoneSnapshot_Version(t *testing.T) {
+	if _, err := ReadZoneSnapshot(strings.NewReader(`{"version": 2, "records": []}`)); err == nil {
+		t.Error("ReadZoneSnapshot accepted an unsupported version")
+	}
+}
@@ -17,7 +17,7 @@ type User struct {
 	TelVerified string `json:"telephone_verified"`
 	WeixinBinded string `json:"weixin_binded"`
 	AgentPending bool `json:"agent_pending"`
-	Balance int `json:"balance"`
+	Balance float64 `json:"balance"`
 	SmsBalance int `json:"smsbalance"`
 	UserGrade string `json:"user_grade"`
 }
@@ -43,7 +43,36 @@ func userAction(action string) string {
 	return fmt.Sprintf("User.%s", action)
 }
 
-func (s *DomainsService) GetUserInfo() (User, *Response, error) {
+func init() {
+	registerReadOnly(userAction("Log"))
+}
+
+// UsersService handles communication with the user account related
+// methods of the dnspod API.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/accounts.html
+type UsersService struct {
+	client *Client
+}
+
+// UserModification holds the account attributes changed by UsersService.Update.
+// Empty attributes are left unchanged.
+type UserModification struct {
+	RealName string
+	Nick     string
+	Tel      string
+	IM       string
+}
+
+type userLogWrapper struct {
+	Status Status   `json:"status"`
+	Log    []string `json:"log"`
+}
+
+// Detail fetches the details of the authenticated user.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/accounts.html#user-detail
+func (s *UsersService) Detail() (User, *Response, error) {
 	path := userAction("Detail")
 	wrapper := userWrapper{}
 
@@ -53,8 +82,80 @@ func (s *DomainsService) GetUserInfo() (User, *Response, error) {
 	if err != nil {
 		return User{}, res, err
 	}
-	if wrapper.Status.Code != "1" {
-		return User{}, res, fmt.Errorf("unexpected error: %s", wrapper.Status.Message)
-	}
 	return wrapper.Info.User, res, err
 }
+
+// Update modifies the account attributes of the authenticated user.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/accounts.html#user-modify
+func (s *UsersService) Update(m UserModification) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	if m.RealName != "" {
+		payload.Set("real_name", m.RealName)
+	}
+	if m.Nick != "" {
+		payload.Set("nick", m.Nick)
+	}
+	if m.Tel != "" {
+		payload.Set("telephone", m.Tel)
+	}
+	if m.IM != "" {
+		payload.Set("im", m.IM)
+	}
+
+	return s.client.post(userAction("Modify"), payload, nil)
+}
+
+// UpdatePassword changes the password of the authenticated user.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/accounts.html#user-userpasswd
+func (s *UsersService) UpdatePassword(oldPassword, newPassword string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("old_password", oldPassword)
+	payload.Set("new_password", newPassword)
+
+	return s.client.post(userAction("Userpasswd"), payload, nil)
+}
+
+// UpdateEmail changes the email address of the authenticated user.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/accounts.html#user-usermail
+func (s *UsersService) UpdateEmail(oldEmail, newEmail, password string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("old_email", oldEmail)
+	payload.Set("new_email", newEmail)
+	payload.Set("password", password)
+
+	return s.client.post(userAction("Usermail"), payload, nil)
+}
+
+// SendTelephoneVerifyCode sends a verification code to a telephone number.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/accounts.html#telephoneverify-code
+func (s *UsersService) SendTelephoneVerifyCode(telephone string) (*Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+	payload.Set("telephone", telephone)
+
+	return s.client.post("Telephoneverify.Code", payload, nil)
+}
+
+// Log fetches the operation log of the authenticated user, most recent first.
+//
+// dnspod API docs: https://www.dnspod.cn/docs/accounts.html#user-log
+func (s *UsersService) Log() ([]string, *Response, error) {
+	payload := newPayLoad(s.client.CommonParams)
+
+	wrapper := userLogWrapper{}
+	res, err := s.client.post(userAction("Log"), payload, &wrapper)
+	if err != nil {
+		return nil, res, err
+	}
+	return wrapper.Log, res, nil
+}
+
+// GetUserInfo fetches the details of the authenticated user.
+//
+// Deprecated: use UsersService.Detail.
+func (s *DomainsService) GetUserInfo() (User, *Response, error) {
+	return s.client.Users.Detail()
+}
@@ -0,0 +1,93 @@
+package dnspod
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestUsersService_Detail_balances(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/User.Detail", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprint(w, `{
+			"status": {"code": "1", "message": "Action completed successful"},
+			"info": {
+				"user": {
+					"id": "625033",
+					"email": "api@dnspod.com",
+					"balance": "12.50",
+					"smsbalance": 5,
+					"user_grade": "DP_Free"
+				}
+			}}`)
+	})
+
+	user, _, err := client.Users.Detail()
+	if err != nil {
+		t.Fatalf("Users.Detail returned error: %v", err)
+	}
+	if user.Balance != 12.5 || user.SmsBalance != 5 {
+		t.Errorf("Users.Detail returned balances %v/%d, want 12.5/5", user.Balance, user.SmsBalance)
+	}
+
+	forwarded, _, err := client.Domains.GetUserInfo()
+	if err != nil || !reflect.DeepEqual(forwarded, user) {
+		t.Errorf("Domains.GetUserInfo returned %+v, %v, want %+v", forwarded, err, user)
+	}
+}
+
+func TestUsersService_Update(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/User.Modify", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "nick": "DNSPod 先生", "im": "10000000"})
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+
+	if _, err := client.Users.Update(UserModification{Nick: "DNSPod 先生", IM: "10000000"}); err != nil {
+		t.Errorf("Users.Update returned error: %v", err)
+	}
+}
+
+func TestUsersService_UpdatePassword(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/User.Userpasswd", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"login_token": "dnspod login token", "old_password": "old", "new_password": "new"})
+		fmt.Fprint(w, `{"status": {"code":"1"}}`)
+	})
+
+	var entries []LogEntry
+	client.Hook = func(entry LogEntry) { entries = append(entries, entry) }
+	client.LogBodies = true
+
+	if _, err := client.Users.UpdatePassword("old", "new"); err != nil {
+		t.Errorf("Users.UpdatePassword returned error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Payload.Get("new_password") != redactedValue {
+		t.Errorf("Hook received %+v, want the passwords redacted", entries)
+	}
+}
+
+func TestUsersService_Log(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/User.Log", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"code":"1"},"log":["2015-01-18 20:07:29: (1.2.3.4) 修改密码"]}`)
+	})
+
+	log, _, err := client.Users.Log()
+	if err != nil {
+		t.Fatalf("Users.Log returned error: %v", err)
+	}
+	if len(log) != 1 {
+		t.Errorf("Users.Log returned %v", log)
+	}
+}
@@ -0,0 +1,159 @@
+package dnspod
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// RecordEventType is the kind of change of a RecordEvent.
+type RecordEventType string
+
+const (
+	RecordAdded    RecordEventType = "added"
+	RecordModified RecordEventType = "modified"
+	RecordRemoved  RecordEventType = "removed"
+	RecordWatchErr RecordEventType = "error" // polling a domain failed, see Err
+)
+
+// RecordEvent is a change of a record seen by a Watcher.
+type RecordEvent struct {
+	Type     RecordEventType
+	DomainID string
+	Record   Record  // the record after the change, or before it for RecordRemoved
+	Previous *Record // the record before the change, for RecordModified
+	Err      error   // for RecordWatchErr
+}
+
+// Watcher polls the records of domains and reports their changes, e.g.
+//
+//	w := client.Domains.NewWatcher(time.Minute, "1", "2")
+//	for event := range w.Watch(ctx) {
+//		log.Printf("%s %s %s", event.Type, event.Record.Name, event.Record.Type)
+//	}
+//
+// The records of a domain are only listed when the updated_on of Domain.Info changed
+// since the previous poll. The first poll of a domain records its state without events.
+// Polls bypass the Cache, so that changes are seen as soon as dnspod reports them.
+// A Watcher must not be used concurrently.
+type Watcher struct {
+	domains   *DomainsService
+	domainIDs []string
+	interval  time.Duration
+
+	updatedOn map[string]string            // by domain ID
+	records   map[string]map[string]Record // by domain ID and record ID
+}
+
+// DefaultWatchInterval is the interval of the Watchers created with an interval of zero or less.
+const DefaultWatchInterval = time.Minute
+
+// NewWatcher returns a Watcher polling the given domains every interval,
+// DefaultWatchInterval if interval is zero or less.
+func (s *DomainsService) NewWatcher(interval time.Duration, domainIDs ...string) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	return &Watcher{
+		domains:   s,
+		domainIDs: domainIDs,
+		interval:  interval,
+		updatedOn: map[string]string{},
+		records:   map[string]map[string]Record{},
+	}
+}
+
+// Watch polls the domains until ctx is done, then closes the returned channel.
+// Errors are reported as RecordWatchErr events, the domain being polled again next time.
+func (w *Watcher) Watch(ctx context.Context) <-chan RecordEvent {
+	events := make(chan RecordEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			for _, domainID := range w.domainIDs {
+				changes, err := w.PollDomain(ctx, domainID)
+				if err != nil && ctx.Err() == nil {
+					changes = append(changes, RecordEvent{Type: RecordWatchErr, DomainID: domainID, Err: err})
+				}
+				for _, event := range changes {
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// PollDomain polls a domain once and returns the changes of its records since the previous poll.
+func (w *Watcher) PollDomain(ctx context.Context, domainID string) ([]RecordEvent, error) {
+	ctx = NoCache(ctx)
+	payload := newPayLoad(w.domains.client.CommonParams)
+	payload.Set("domain_id", domainID)
+	returnedDomain := domainWrapper{}
+	if _, err := w.domains.client.DoContext(ctx, "POST", domainAction("Info"), payload, &returnedDomain); err != nil {
+		return nil, err
+	}
+	updatedOn := returnedDomain.Domain.UpdatedOn
+	previous, seen := w.records[domainID]
+	if seen && updatedOn != "" && updatedOn == w.updatedOn[domainID] {
+		return nil, nil
+	}
+
+	list, err := w.domains.listAllRecords(ctx, domainID)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]Record, len(list))
+	for _, r := range list {
+		current[r.ID] = r
+	}
+	w.records[domainID], w.updatedOn[domainID] = current, updatedOn
+	if !seen {
+		return nil, nil
+	}
+	return diffRecordEvents(domainID, previous, list, current), nil
+}
+
+// diffRecordEvents returns the events turning the previous records into the current ones,
+// the added and modified ones in the order of list, then the removed ones by record ID.
+func diffRecordEvents(domainID string, previous map[string]Record, list []Record, current map[string]Record) []RecordEvent {
+	var events []RecordEvent
+	for _, r := range list {
+		before, ok := previous[r.ID]
+		switch {
+		case !ok:
+			events = append(events, RecordEvent{Type: RecordAdded, DomainID: domainID, Record: r})
+		case watchedAttributes(before) != watchedAttributes(r):
+			events = append(events, RecordEvent{Type: RecordModified, DomainID: domainID, Record: r, Previous: &before})
+		}
+	}
+	var removed []Record
+	for id, r := range previous {
+		if _, ok := current[id]; !ok {
+			removed = append(removed, r)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return lessID(removed[i].ID, removed[j].ID) })
+	for _, r := range removed {
+		events = append(events, RecordEvent{Type: RecordRemoved, DomainID: domainID, Record: r})
+	}
+	return events
+}
+
+// watchedAttributes drops the attributes changing without an edit of the record.
+func watchedAttributes(r Record) Record {
+	r.MonitorStatus = ""
+	r.UpdateOn = ""
+	return r
+}
@@ -0,0 +1,122 @@
+package dnspod
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// watchHandlers serves the records of domain 1, *updatedOn being its updated_on.
+func watchHandlers(updatedOn *string, records *string, lists *int) {
+	mux.HandleFunc("/Domain.Info", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("domain_id") != "1" {
+			fmt.Fprint(w, `{"status": {"code":"6","message":"Domain id invalid"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"status": {"code":"1"},"domain": {"id":1, "name":"example.com", "updated_on":"%s"}}`, *updatedOn)
+	})
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		*lists++
+		fmt.Fprintf(w, `{"status": {"code":"1"},"info": {"record_total": "%d"},"records": [%s]}`, strings.Count(*records, "{"), *records)
+	})
+}
+
+func TestWatcher_PollDomain(t *testing.T) {
+	setup()
+	defer teardown()
+
+	updatedOn, lists := "2026-10-01 10:00:00", 0
+	records := `{"id":"1","name":"www","type":"A","value":"1.1.1.1","ttl":"600"},{"id":"2","name":"old","type":"A","value":"2.2.2.2","ttl":"600"}`
+	watchHandlers(&updatedOn, &records, &lists)
+
+	w := client.Domains.NewWatcher(time.Minute, "1")
+	events, err := w.PollDomain(context.Background(), "1")
+	if err != nil || len(events) != 0 {
+		t.Fatalf("Watcher.PollDomain returned %v, %v, want the initial state without events", events, err)
+	}
+
+	// The domain is unchanged: its records are not listed.
+	records = `{"id":"1","name":"www","type":"A","value":"9.9.9.9","ttl":"600"}`
+	if events, _ := w.PollDomain(context.Background(), "1"); len(events) != 0 || lists != 1 {
+		t.Errorf("Watcher.PollDomain of an unchanged domain returned %v after %d lists", events, lists)
+	}
+
+	updatedOn = "2026-10-01 10:05:00"
+	records = `{"id":"1","name":"www","type":"A","value":"9.9.9.9","ttl":"600","monitor_status":"Ok"},{"id":"3","name":"new","type":"A","value":"3.3.3.3","ttl":"600"}`
+	events, err = w.PollDomain(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Watcher.PollDomain returned error: %v", err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, string(e.Type)+" "+e.Record.ID)
+	}
+	if want := "modified 1, added 3, removed 2"; strings.Join(got, ", ") != want {
+		t.Errorf("Watcher.PollDomain returned %s, want %s", strings.Join(got, ", "), want)
+	}
+	if events[0].Previous == nil || events[0].Previous.Value != "1.1.1.1" || events[0].DomainID != "1" {
+		t.Errorf("Watcher.PollDomain returned modified event %+v", events[0])
+	}
+
+	// A change of the monitoring status alone is no modification.
+	updatedOn = "2026-10-01 10:10:00"
+	records = `{"id":"1","name":"www","type":"A","value":"9.9.9.9","ttl":"600","monitor_status":"Down"},{"id":"3","name":"new","type":"A","value":"3.3.3.3","ttl":"600"}`
+	if events, _ := w.PollDomain(context.Background(), "1"); len(events) != 0 {
+		t.Errorf("Watcher.PollDomain returned %v, want no events", events)
+	}
+}
+
+func TestWatcher_Watch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	updatedOn, lists := "2026-10-01 10:00:00", 0
+	records := `{"id":"1","name":"www","type":"A","value":"1.1.1.1","ttl":"600"}`
+	watchHandlers(&updatedOn, &records, &lists)
+
+	w := client.Domains.NewWatcher(time.Millisecond, "1", "404")
+	ctx, cancel := context.WithCancel(context.Background())
+	events := w.Watch(ctx)
+
+	// Domain 404 is not served.
+	if event := <-events; event.Type != RecordWatchErr || event.DomainID != "404" || event.Err == nil {
+		t.Errorf("Watcher.Watch sent %+v, want an error for domain 404", event)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatcher_PollDomain_cache(t *testing.T) {
+	setup()
+	defer teardown()
+
+	updatedOn, lists := "2026-10-01 10:00:00", 0
+	records := `{"id":"2","name":"a","type":"A","value":"2.2.2.2"},{"id":"9","name":"b","type":"A","value":"9.9.9.9"},{"id":"10","name":"c","type":"A","value":"10.10.10.10"}`
+	watchHandlers(&updatedOn, &records, &lists)
+	client.Use(NewCache(NewLRUCache(16), time.Minute).Middleware)
+
+	// A zero interval defaults to DefaultWatchInterval instead of panicking in Watch.
+	w := client.Domains.NewWatcher(0, "1")
+	if w.interval != DefaultWatchInterval {
+		t.Errorf("NewWatcher(0) interval = %v, want %v", w.interval, DefaultWatchInterval)
+	}
+	w.PollDomain(context.Background(), "1")
+	client.Domains.ListAllRecords("1") // cached, unless the watcher bypasses the cache
+
+	updatedOn, records = "2026-10-01 10:05:00", ``
+	events, err := w.PollDomain(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Watcher.PollDomain returned error: %v", err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, string(e.Type)+" "+e.Record.ID)
+	}
+	if want := "removed 2, removed 9, removed 10"; strings.Join(got, ", ") != want {
+		t.Errorf("Watcher.PollDomain returned %s, want %s", strings.Join(got, ", "), want)
+	}
+}
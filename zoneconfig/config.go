@@ -0,0 +1,258 @@
+// Package zoneconfig defines a versioned, declarative representation of dnspod zones,
+// to be kept in Git as their source of truth, e.g.
+//
+//	version: 1
+//	groups:
+//	  - name: production
+//	domains:
+//	  - name: example.com
+//	    group: production
+//	    records:
+//	      - {name: www, type: A, value: 1.2.3.4, ttl: 600}
+//	      - {name: www, type: A, line: 电信, value: 5.6.7.8, weight: 50}
+//	      - {name: "@", type: MX, value: mail.example.com., mx: 10}
+//	      - {name: old, type: CNAME, value: legacy.example.net., enabled: false, remark: to remove}
+//
+// Files are YAML or JSON, the field names being the same in both. Load reads and
+// validates them, and Dump generates them from the live domains of an account.
+// The records are applied through DomainsService.Plan or Reconcile, while the groups
+// and domain remarks are dump-only: they document the account and are never applied.
+package zoneconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/decker502/dnspod-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the version of the format written by this package, and the latest it reads.
+const Version = 1
+
+// Config is the content of a zone configuration file.
+type Config struct {
+	Version int      `json:"version" yaml:"version"`
+	Groups  []Group  `json:"groups,omitempty" yaml:"groups,omitempty"` // dump-only
+	Domains []Domain `json:"domains" yaml:"domains"`
+}
+
+// Group is a domain group of the account.
+type Group struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// Domain is a domain and its records. The apex NS records, managed by dnspod, are left out.
+type Domain struct {
+	Name    string   `json:"name" yaml:"name"`
+	Group   string   `json:"group,omitempty" yaml:"group,omitempty"`   // name of one of Config.Groups, dump-only
+	Remark  string   `json:"remark,omitempty" yaml:"remark,omitempty"` // dump-only
+	Records []Record `json:"records" yaml:"records"`
+}
+
+// Record is a record of a domain.
+type Record struct {
+	Name    string `json:"name" yaml:"name"` // @ for the apex
+	Type    string `json:"type" yaml:"type"`
+	Line    string `json:"line,omitempty" yaml:"line,omitempty"` // dnspod.DefaultLine when empty
+	Value   string `json:"value" yaml:"value"`
+	TTL     int    `json:"ttl,omitempty" yaml:"ttl,omitempty"` // the one of the domain when 0
+	MX      int    `json:"mx,omitempty" yaml:"mx,omitempty"`   // MX records only
+	Weight  *int   `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Remark  string `json:"remark,omitempty" yaml:"remark,omitempty"`   // left unchanged when empty
+	Enabled *bool  `json:"enabled,omitempty" yaml:"enabled,omitempty"` // true when unset
+}
+
+// recordTypes are the record types of dnspod.
+var recordTypes = map[string]bool{
+	"A": true, "AAAA": true, "CNAME": true, "MX": true, "TXT": true, "NS": true,
+	"SRV": true, "CAA": true, "HTTPS": true, "SVCB": true, "PTR": true, "SPF": true,
+	"显性URL": true, "隐性URL": true,
+}
+
+// Parse parses and validates a configuration, in YAML or JSON.
+// Unknown fields are rejected, so that misspelled ones are not silently ignored.
+func Parse(data []byte) (*Config, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	config := &Config{}
+	if err := dec.Decode(config); err != nil {
+		return nil, fmt.Errorf("zoneconfig: %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Load reads, parses and validates a configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Validate checks a configuration, returning all its problems joined.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("zoneconfig: "+format, args...))
+	}
+
+	switch {
+	case c.Version == 0:
+		fail("version: missing")
+	case c.Version < 0 || c.Version > Version:
+		fail("version: unsupported version %d, the latest being %d", c.Version, Version)
+	}
+
+	groups := map[string]bool{}
+	for i, g := range c.Groups {
+		switch {
+		case g.Name == "":
+			fail("groups[%d].name: missing", i)
+		case groups[g.Name]:
+			fail("groups[%d].name: duplicate group %s", i, g.Name)
+		}
+		groups[g.Name] = true
+	}
+
+	domains := map[string]bool{}
+	for i, d := range c.Domains {
+		name := strings.ToLower(strings.TrimSuffix(d.Name, "."))
+		switch {
+		case name == "":
+			fail("domains[%d].name: missing", i)
+		case domains[name]:
+			fail("domains[%d].name: duplicate domain %s", i, d.Name)
+		}
+		domains[name] = true
+		if d.Group != "" && !groups[d.Group] {
+			fail("domains[%d].group: unknown group %s", i, d.Group)
+		}
+
+		records := map[string]bool{}
+		for j, r := range d.Records {
+			path := fmt.Sprintf("domains[%d].records[%d]", i, j)
+			if err := r.validate(); err != nil {
+				fail("%s.%v", path, err)
+				continue
+			}
+			key := strings.ToLower(r.Name) + "|" + strings.ToUpper(r.Type) + "|" + r.line() + "|" + r.Value
+			if records[key] {
+				fail("%s: duplicate record %s %s %s", path, r.Name, r.Type, r.Value)
+			}
+			records[key] = true
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r Record) validate() error {
+	typ := strings.ToUpper(r.Type)
+	switch {
+	case r.Name == "":
+		return errors.New("name: missing, @ being the apex")
+	case strings.HasPrefix(r.Name, ".") || strings.HasSuffix(r.Name, "."):
+		return fmt.Errorf("name: %s is not relative to the domain", r.Name)
+	case !recordTypes[typ]:
+		return fmt.Errorf("type: unknown type %q", r.Type)
+	case r.Value == "":
+		return errors.New("value: missing")
+	case r.TTL < 0 || r.TTL > 604800:
+		return fmt.Errorf("ttl: %d out of 1 to 604800", r.TTL)
+	case typ == "MX" && (r.MX < 1 || r.MX > 20):
+		return fmt.Errorf("mx: %d out of 1 to 20", r.MX)
+	case typ != "MX" && r.MX != 0:
+		return errors.New("mx: set on a record other than MX")
+	case r.Weight != nil && (*r.Weight < 0 || *r.Weight > 100):
+		return fmt.Errorf("weight: %d out of 0 to 100", *r.Weight)
+	case typ == "NS" && r.Name == "@":
+		return errors.New("type: the apex NS records are managed by dnspod")
+	}
+	return nil
+}
+
+func (r Record) line() string {
+	if r.Line == "" {
+		return dnspod.DefaultLine
+	}
+	return r.Line
+}
+
+// Domain returns the domain of the configuration with the given name, if any.
+func (c *Config) Domain(name string) (Domain, bool) {
+	name = strings.TrimSuffix(name, ".")
+	for _, d := range c.Domains {
+		if strings.EqualFold(strings.TrimSuffix(d.Name, "."), name) {
+			return d, true
+		}
+	}
+	return Domain{}, false
+}
+
+// DNSPodRecords returns the records of a domain as desired by DomainsService.Plan,
+// Reconcile or DriftReport.
+func (d Domain) DNSPodRecords() []dnspod.Record {
+	records := make([]dnspod.Record, 0, len(d.Records))
+	for _, r := range d.Records {
+		records = append(records, r.DNSPod())
+	}
+	return records
+}
+
+// DNSPod returns the record as a dnspod.Record.
+func (r Record) DNSPod() dnspod.Record {
+	record := dnspod.Record{
+		Name:   r.Name,
+		Type:   strings.ToUpper(r.Type),
+		Line:   r.line(),
+		Value:  r.Value,
+		Remark: r.Remark,
+		Status: "enable",
+	}
+	if r.TTL != 0 {
+		record.TTL = strconv.Itoa(r.TTL)
+	}
+	if r.MX != 0 {
+		record.MX = strconv.Itoa(r.MX)
+	}
+	if r.Weight != nil {
+		record.Weight = strconv.Itoa(*r.Weight)
+	}
+	if r.Enabled != nil && !*r.Enabled {
+		record.Status = "disable"
+	}
+	return record
+}
+
+// FromDNSPod returns a live record as a Record, the attributes having their
+// default value being left out.
+func FromDNSPod(r dnspod.Record) Record {
+	record := Record{Name: r.Name, Type: r.Type, Value: r.Value, Remark: r.Remark}
+	if r.Line != dnspod.DefaultLine {
+		record.Line = r.Line
+	}
+	record.TTL, _ = strconv.Atoi(r.TTL)
+	if strings.ToUpper(r.Type) == "MX" {
+		record.MX, _ = strconv.Atoi(r.MX)
+	}
+	if weight, err := strconv.Atoi(r.Weight); err == nil {
+		record.Weight = &weight
+	}
+	if !dnspod.RecordEnabled(r) {
+		enabled := false
+		record.Enabled = &enabled
+	}
+	return record
+}
@@ -0,0 +1,131 @@
+package zoneconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/decker502/dnspod-go"
+)
+
+const exampleYAML = `version: 1
+groups:
+  - name: production
+domains:
+  - name: example.com
+    group: production
+    remark: main site
+    records:
+      - {name: www, type: A, value: 1.2.3.4, ttl: 600}
+      - {name: www, type: A, line: 电信, value: 5.6.7.8, weight: 50}
+      - {name: "@", type: MX, value: mail.example.com., mx: 10}
+      - {name: old, type: CNAME, value: legacy.example.net., enabled: false, remark: to remove}
+`
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(exampleYAML))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	weight, disabled := 50, false
+	want := &Config{
+		Version: 1,
+		Groups:  []Group{{Name: "production"}},
+		Domains: []Domain{{
+			Name:   "example.com",
+			Group:  "production",
+			Remark: "main site",
+			Records: []Record{
+				{Name: "www", Type: "A", Value: "1.2.3.4", TTL: 600},
+				{Name: "www", Type: "A", Line: "电信", Value: "5.6.7.8", Weight: &weight},
+				{Name: "@", Type: "MX", Value: "mail.example.com.", MX: 10},
+				{Name: "old", Type: "CNAME", Value: "legacy.example.net.", Enabled: &disabled, Remark: "to remove"},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("Parse returned %+v, want %+v", config, want)
+	}
+
+	// JSON is read as well.
+	var b strings.Builder
+	config.WriteJSON(&b)
+	fromJSON, err := Parse([]byte(b.String()))
+	if err != nil || !reflect.DeepEqual(fromJSON, want) {
+		t.Errorf("Parse of the JSON dump returned %+v, %v", fromJSON, err)
+	}
+}
+
+func TestDomain_DNSPodRecords(t *testing.T) {
+	config, _ := Parse([]byte(exampleYAML))
+	domain, ok := config.Domain("EXAMPLE.com.")
+	if !ok {
+		t.Fatalf("Config.Domain did not find example.com")
+	}
+
+	want := []dnspod.Record{
+		{Name: "www", Type: "A", Line: dnspod.DefaultLine, Value: "1.2.3.4", TTL: "600", Status: "enable"},
+		{Name: "www", Type: "A", Line: "电信", Value: "5.6.7.8", Weight: "50", Status: "enable"},
+		{Name: "@", Type: "MX", Line: dnspod.DefaultLine, Value: "mail.example.com.", MX: "10", Status: "enable"},
+		{Name: "old", Type: "CNAME", Line: dnspod.DefaultLine, Value: "legacy.example.net.", Remark: "to remove", Status: "disable"},
+	}
+	if got := domain.DNSPodRecords(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Domain.DNSPodRecords returned %+v, want %+v", got, want)
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	tests := []struct {
+		config string
+		want   []string
+	}{
+		{"domains: []\n", []string{"version: missing"}},
+		{"version: 2\ndomains: []\n", []string{"version: unsupported version 2"}},
+		{"version: 1\ndomains: []\nzones: []\n", []string{"field zones not found"}},
+		{`version: 1
+groups: [{name: a}, {name: a}]
+domains:
+  - name: example.com
+    group: b
+    records:
+      - {name: www, type: A, value: 1.2.3.4}
+      - {name: WWW, type: a, value: 1.2.3.4}
+      - {name: www., type: A, value: 1.2.3.4}
+      - {name: www, type: B, value: 1.2.3.4}
+      - {name: www, type: A, value: ""}
+      - {name: www, type: A, value: 1.2.3.5, ttl: 700000}
+      - {name: "@", type: MX, value: mail.example.com.}
+      - {name: www, type: A, value: 1.2.3.6, mx: 10}
+      - {name: www, type: A, value: 1.2.3.7, weight: 101}
+      - {name: "@", type: NS, value: ns1.example.net.}
+  - name: example.com.
+    records: []
+`, []string{
+			"groups[1].name: duplicate group a",
+			"domains[0].group: unknown group b",
+			"domains[0].records[1]: duplicate record WWW a 1.2.3.4",
+			"domains[0].records[2].name: www. is not relative to the domain",
+			`domains[0].records[3].type: unknown type "B"`,
+			"domains[0].records[4].value: missing",
+			"domains[0].records[5].ttl: 700000 out of 1 to 604800",
+			"domains[0].records[6].mx: 0 out of 1 to 20",
+			"domains[0].records[7].mx: set on a record other than MX",
+			"domains[0].records[8].weight: 101 out of 0 to 100",
+			"domains[0].records[9].type: the apex NS records are managed by dnspod",
+			"domains[1].name: duplicate domain example.com.",
+		}},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.config))
+		if err == nil {
+			t.Errorf("Parse(%q) returned no error", tt.config)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Parse(%q) returned %v, missing %q", tt.config, err, want)
+			}
+		}
+	}
+}
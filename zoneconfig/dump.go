@@ -0,0 +1,103 @@
+package zoneconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/decker502/dnspod-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Dump generates the configuration of the live domains of an account, all of them
+// unless names are given. The records are sorted by name, type, line and value,
+// so that dumps of the same zones compare equal.
+func Dump(client *dnspod.Client, names ...string) (*Config, error) {
+	groups, _, err := client.Domains.ListGroups()
+	if err != nil {
+		return nil, fmt.Errorf("zoneconfig: list the domain groups: %w", err)
+	}
+	userGroups := map[string]string{} // name by ID, system groups left out
+	for _, g := range groups {
+		if g.Type != "system" {
+			userGroups[g.ID] = g.Name
+		}
+	}
+
+	domains, err := client.Domains.ListAllDomains()
+	if err != nil {
+		return nil, fmt.Errorf("zoneconfig: list the domains: %w", err)
+	}
+	if len(names) > 0 {
+		byName := map[string]dnspod.Domain{}
+		for _, d := range domains {
+			byName[strings.ToLower(d.Name)] = d
+		}
+		domains = domains[:0]
+		for _, name := range names {
+			d, ok := byName[strings.ToLower(strings.TrimSuffix(name, "."))]
+			if !ok {
+				return nil, fmt.Errorf("zoneconfig: no domain %s in the account", name)
+			}
+			domains = append(domains, d)
+		}
+	}
+
+	config := &Config{Version: Version, Domains: []Domain{}}
+	used := map[string]bool{}
+	for _, d := range domains {
+		live, err := client.Domains.ListAllRecords(d.ID)
+		if err != nil {
+			return nil, fmt.Errorf("zoneconfig: list the records of %s: %w", d.Name, err)
+		}
+		domain := Domain{Name: d.Name, Group: userGroups[d.GroupID], Remark: d.Remark, Records: []Record{}}
+		for _, r := range live {
+			if r.Name == "@" && strings.ToUpper(r.Type) == "NS" {
+				continue
+			}
+			domain.Records = append(domain.Records, FromDNSPod(r))
+		}
+		sort.SliceStable(domain.Records, func(i, j int) bool {
+			a, b := domain.Records[i], domain.Records[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+			if a.line() != b.line() {
+				return a.line() < b.line()
+			}
+			return a.Value < b.Value
+		})
+		if domain.Group != "" && !used[domain.Group] {
+			used[domain.Group] = true
+			config.Groups = append(config.Groups, Group{Name: domain.Group})
+		}
+		config.Domains = append(config.Domains, domain)
+	}
+	sort.Slice(config.Groups, func(i, j int) bool { return config.Groups[i].Name < config.Groups[j].Name })
+	return config, nil
+}
+
+// WriteYAML writes the configuration as YAML.
+func (c *Config) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// WriteJSON writes the configuration as JSON.
+func (c *Config) WriteJSON(w io.Writer) error {
+	bs, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(bs, '\n'))
+	return err
+}
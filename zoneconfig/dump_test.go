@@ -0,0 +1,124 @@
+package zoneconfig
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/decker502/dnspod-go"
+	"github.com/decker502/dnspod-go/dnspodtest"
+)
+
+func TestDump(t *testing.T) {
+	srv := dnspodtest.NewServer()
+	defer srv.Close()
+	id := srv.AddDomain("example.com",
+		dnspod.Record{Name: "www", Type: "A", Value: "1.2.3.4", TTL: "600"},
+		dnspod.Record{Name: "api", Type: "CNAME", Line: "电信", Value: "lb.example.net.", TTL: "300", Weight: "20", Remark: "load balancer"},
+		dnspod.Record{Name: "@", Type: "MX", Value: "mail.example.com.", MX: "10", TTL: "600", Status: "disable"},
+	)
+	srv.AddDomain("example.net")
+	client := srv.Client()
+
+	config, err := Dump(client, "example.com")
+	if err != nil {
+		t.Fatalf("Dump returned error: %v", err)
+	}
+
+	weight, disabled := 20, false
+	want := &Config{
+		Version: Version,
+		Domains: []Domain{{
+			Name: "example.com",
+			Records: []Record{
+				{Name: "@", Type: "MX", Value: "mail.example.com.", TTL: 600, MX: 10, Enabled: &disabled},
+				{Name: "api", Type: "CNAME", Line: "电信", Value: "lb.example.net.", TTL: 300, Weight: &weight, Remark: "load balancer"},
+				{Name: "www", Type: "A", Value: "1.2.3.4", TTL: 600},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("Dump returned %+v, want %+v", config, want)
+	}
+
+	// The dump is valid, and describes the live records.
+	var b bytes.Buffer
+	if err := config.WriteYAML(&b); err != nil {
+		t.Fatalf("Config.WriteYAML returned error: %v", err)
+	}
+	loaded, err := Parse(b.Bytes())
+	if err != nil {
+		t.Fatalf("Parse of the dump returned error: %v\n%s", err, b.String())
+	}
+	plan, err := client.Domains.Plan(context.Background(), id, loaded.Domains[0].DNSPodRecords())
+	if err != nil || !plan.Empty() {
+		t.Errorf("Domains.Plan of the dump returned %v, %v", plan, err)
+	}
+
+	if _, err := Dump(client, "example.org"); err == nil {
+		t.Errorf("Dump of a domain missing from the account returned no error")
+	}
+	if all, err := Dump(client); err != nil || len(all.Domains) != 2 {
+		t.Errorf("Dump of all the domains returned %+v, %v", all, err)
+	}
+}
+
+func TestDump_recordTypes(t *testing.T) {
+	srv := dnspodtest.NewServer()
+	defer srv.Close()
+	srv.AddDomain("example.com",
+		dnspod.Record{Name: "@", Type: "HTTPS", Value: "1 . alpn=h2", TTL: "600"},
+		dnspod.Record{Name: "_dns", Type: "SVCB", Value: "1 dns.example.com. alpn=dot", TTL: "600"},
+		dnspod.Record{Name: "4", Type: "PTR", Value: "www.example.com.", TTL: "600"},
+	)
+
+	config, err := Dump(srv.Client(), "example.com")
+	if err != nil {
+		t.Fatalf("Dump returned error: %v", err)
+	}
+	var b bytes.Buffer
+	if err := config.WriteYAML(&b); err != nil {
+		t.Fatalf("Config.WriteYAML returned error: %v", err)
+	}
+	if _, err := Parse(b.Bytes()); err != nil {
+		t.Errorf("Parse of the dump returned error: %v\n%s", err, b.String())
+	}
+}
+
+func TestDump_remark(t *testing.T) {
+	srv := dnspodtest.NewServer()
+	defer srv.Close()
+	id := srv.AddDomain("example.com",
+		dnspod.Record{Name: "www", Type: "A", Value: "1.2.3.4", TTL: "600", Remark: "web"},
+	)
+	client := srv.Client()
+
+	config, err := Dump(client)
+	if err != nil {
+		t.Fatalf("Dump returned error: %v", err)
+	}
+	var b bytes.Buffer
+	if err := config.WriteYAML(&b); err != nil {
+		t.Fatalf("Config.WriteYAML returned error: %v", err)
+	}
+	loaded, err := Parse(b.Bytes())
+	if err != nil {
+		t.Fatalf("Parse of the dump returned error: %v\n%s", err, b.String())
+	}
+	if got := loaded.Domains[0].Records[0].Remark; got != "web" {
+		t.Errorf("Parse of the dump returned remark %q, want %q", got, "web")
+	}
+
+	// A changed remark is applied to the live record.
+	loaded.Domains[0].Records[0].Remark = "web server"
+	results, err := client.Domains.Reconcile(context.Background(), id, loaded.Domains[0].DNSPodRecords(), dnspod.ReconcileOptions{})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Domains.Reconcile returned %+v, %v", results, err)
+	}
+	for _, r := range srv.Records(id) {
+		if r.Name == "www" && r.Remark != "web server" {
+			t.Errorf("Domains.Reconcile set remark %q, want %q", r.Remark, "web server")
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package dnspod
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseZoneFile reads the records of an RFC 1035 zone file, e.g. as kept in Git as the
+// source of truth of a domain. Names are made relative to the domain, which defaults to
+// the first $ORIGIN of the file; host names in values are made absolute, with a trailing dot.
+//
+// The SOA record is skipped, records are on the default line, and their TTL is the one
+// of the record, or $TTL, or left empty. $INCLUDE is not supported.
+func ParseZoneFile(r io.Reader, domain string) ([]Record, error) {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	p := zoneParser{domain: domain, origin: domain}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+
+	var (
+		records []Record
+		fields  []string
+		depth   int // of the parentheses
+		start   int // line of the entry, for errors
+		indent  bool
+	)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if depth == 0 {
+			start, indent = n, line != "" && (line[0] == ' ' || line[0] == '\t')
+		}
+		tokens, err := zoneTokens(line, &depth)
+		if err != nil {
+			return nil, fmt.Errorf("zone file line %d: %v", n, err)
+		}
+		fields = append(fields, tokens...)
+		if depth > 0 || len(fields) == 0 {
+			continue
+		}
+
+		record, ok, err := p.entry(fields, indent)
+		if err != nil {
+			return nil, fmt.Errorf("zone file line %d: %v", start, err)
+		}
+		if ok {
+			records = append(records, record)
+		}
+		fields = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("zone file line %d: unbalanced parentheses", start)
+	}
+	return records, nil
+}
+
+type zoneParser struct {
+	domain string // names are relative to
+	origin string // relative names are relative to
+	ttl    string
+	owner  string // of the previous record
+}
+
+// zoneTokens splits a line into fields, dropping comments and parentheses and keeping
+// the quotes of quoted strings.
+func zoneTokens(line string, depth *int) ([]string, error) {
+	var tokens []string
+	var b strings.Builder
+	quoted, escaped := false, false
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, b.String())
+			b.Reset()
+		}
+	}
+	for _, c := range line {
+		switch {
+		case escaped:
+			b.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			b.WriteRune(c)
+			escaped = true
+		case c == '"':
+			b.WriteRune(c)
+			quoted = !quoted
+		case quoted:
+			b.WriteRune(c)
+		case c == ';':
+			flush()
+			return tokens, nil
+		case c == '(' || c == ')':
+			flush()
+			if c == '(' {
+				*depth++
+			} else if *depth--; *depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses")
+			}
+		case c == ' ' || c == '\t':
+			flush()
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quoted string")
+	}
+	flush()
+	return tokens, nil
+}
+
+// entry parses a directive or a record, ok being false for directives and skipped records.
+func (p *zoneParser) entry(fields []string, indent bool) (record Record, ok bool, err error) {
+	switch strings.ToUpper(fields[0]) {
+	case "$ORIGIN":
+		if len(fields) != 2 {
+			return record, false, fmt.Errorf("invalid $ORIGIN")
+		}
+		p.origin = strings.TrimSuffix(strings.ToLower(p.absolute(fields[1])), ".")
+		if p.domain == "" {
+			p.domain = p.origin
+		}
+		return record, false, nil
+	case "$TTL":
+		if len(fields) != 2 {
+			return record, false, fmt.Errorf("invalid $TTL")
+		}
+		p.ttl, err = zoneTTL(fields[1])
+		return record, false, err
+	case "$INCLUDE", "$GENERATE":
+		return record, false, fmt.Errorf("%s is not supported", fields[0])
+	}
+	if p.origin == "" {
+		return record, false, fmt.Errorf("no domain: set $ORIGIN or the domain")
+	}
+
+	if !indent {
+		p.owner, err = p.relative(fields[0])
+		if err != nil {
+			return record, false, err
+		}
+		fields = fields[1:]
+	} else if p.owner == "" {
+		return record, false, fmt.Errorf("no owner name")
+	}
+
+	// The TTL and class may come in either order before the type.
+	record = Record{Name: p.owner, Line: DefaultLine, TTL: p.ttl}
+	for len(fields) > 0 {
+		if strings.EqualFold(fields[0], "IN") {
+			fields = fields[1:]
+		} else if ttl, err := zoneTTL(fields[0]); err == nil {
+			record.TTL, fields = ttl, fields[1:]
+		} else {
+			break
+		}
+	}
+	if len(fields) < 2 {
+		return record, false, fmt.Errorf("no type or value")
+	}
+	record.Type = strings.ToUpper(fields[0])
+	rdata := fields[1:]
+
+	switch record.Type {
+	case "SOA":
+		return record, false, nil
+	case "CNAME", "NS", "PTR":
+		record.Value = p.absolute(rdata[0])
+	case "MX":
+		if len(rdata) != 2 {
+			return record, false, fmt.Errorf("invalid MX value")
+		}
+		record.MX, record.Value = rdata[0], p.absolute(rdata[1])
+	case "SRV":
+		if len(rdata) != 4 {
+			return record, false, fmt.Errorf("invalid SRV value")
+		}
+		record.Value = strings.Join(append(rdata[:3:3], p.absolute(rdata[3])), " ")
+	case "TXT", "SPF":
+		for _, s := range rdata {
+			record.Value += unquoteZoneString(s)
+		}
+	default:
+		record.Value = strings.Join(rdata, " ")
+	}
+	return record, true, nil
+}
+
+// relative returns a name relative to the domain, @ for the domain itself.
+func (p *zoneParser) relative(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSuffix(p.absolute(name), "."))
+	switch {
+	case name == p.domain:
+		return "@", nil
+	case strings.HasSuffix(name, "."+p.domain):
+		return strings.TrimSuffix(name, "."+p.domain), nil
+	}
+	return "", fmt.Errorf("%s is out of the zone %s", name, p.domain)
+}
+
+// absolute returns a host name with its trailing dot.
+func (p *zoneParser) absolute(name string) string {
+	switch {
+	case name == "@":
+		return p.origin + "."
+	case strings.HasSuffix(name, "."):
+		return name
+	case p.origin == "":
+		return name + "."
+	}
+	return name + "." + p.origin + "."
+}
+
+// zoneTTL parses a TTL in seconds, or with the units of BIND, e.g. 1h30m.
+func zoneTTL(s string) (string, error) {
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return strconv.FormatUint(n, 10), nil
+	}
+	units := map[byte]uint64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	var total, n uint64
+	digits := false
+	for i := 0; i < len(s); i++ {
+		c := s[i] | 0x20
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			n, digits = n*10+uint64(s[i]-'0'), true
+		case units[c] > 0 && digits:
+			total, n, digits = total+n*units[c], 0, false
+		default:
+			return "", fmt.Errorf("invalid TTL %q", s)
+		}
+	}
+	if digits || total == 0 {
+		return "", fmt.Errorf("invalid TTL %q", s)
+	}
+	return strconv.FormatUint(total, 10), nil
+}
+
+// unquoteZoneString returns the content of a character string, quoted or not.
+func unquoteZoneString(s string) string {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, `"`), `"`)
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if i+2 < len(s) && isDigit(s[i]) && isDigit(s[i+1]) && isDigit(s[i+2]) {
+				n, _ := strconv.Atoi(s[i : i+3])
+				b.WriteByte(byte(n))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
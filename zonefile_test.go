@@ -0,0 +1,61 @@
+package dnspod
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseZoneFile(t *testing.T) {
+	zone := `$TTL 1h
+@	IN	SOA	ns1.dnspod.net. admin.example.com. (
+		2026101801 ; serial
+		3600 600 86400 300 )
+	IN	NS	f1g1ns1.dnspod.net.
+	IN	MX	10 mail
+www	600	IN	A	1.1.1.1
+	IN	600	A	2.2.2.2 ; round robin
+api.example.com.	CNAME	lb.example.net.
+_sip._tcp	SRV	0 5 5060 sip
+@	TXT	"v=spf1 include:spf.example.net" " -all"
+$ORIGIN sub.example.com.
+host	AAAA	2001:db8::1
+`
+	records, err := ParseZoneFile(strings.NewReader(zone), "example.com")
+	if err != nil {
+		t.Fatalf("ParseZoneFile returned error: %v", err)
+	}
+
+	want := []Record{
+		{Name: "@", Type: "NS", Value: "f1g1ns1.dnspod.net.", Line: DefaultLine, TTL: "3600"},
+		{Name: "@", Type: "MX", MX: "10", Value: "mail.example.com.", Line: DefaultLine, TTL: "3600"},
+		{Name: "www", Type: "A", Value: "1.1.1.1", Line: DefaultLine, TTL: "600"},
+		{Name: "www", Type: "A", Value: "2.2.2.2", Line: DefaultLine, TTL: "600"},
+		{Name: "api", Type: "CNAME", Value: "lb.example.net.", Line: DefaultLine, TTL: "3600"},
+		{Name: "_sip._tcp", Type: "SRV", Value: "0 5 5060 sip.example.com.", Line: DefaultLine, TTL: "3600"},
+		{Name: "@", Type: "TXT", Value: "v=spf1 include:spf.example.net -all", Line: DefaultLine, TTL: "3600"},
+		{Name: "host.sub", Type: "AAAA", Value: "2001:db8::1", Line: DefaultLine, TTL: "3600"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("ParseZoneFile returned %+v, want %+v", records, want)
+	}
+}
+
+func TestParseZoneFile_errors(t *testing.T) {
+	tests := []struct {
+		zone, origin, want string
+	}{
+		{"www A 1.1.1.1\n", "", "line 1: no domain"},
+		{"www.example.net. A 1.1.1.1\n", "example.com", "line 1: www.example.net is out of the zone example.com"},
+		{"\n@ SOA ns. admin. ( 1 2\n", "example.com", "line 2: unbalanced parentheses"},
+		{"$TTL 1x\n", "example.com", `line 1: invalid TTL "1x"`},
+		{"$INCLUDE other.zone\n", "example.com", "line 1: $INCLUDE is not supported"},
+		{"www MX mail\n", "example.com", "line 1: invalid MX value"},
+	}
+	for _, tt := range tests {
+		_, err := ParseZoneFile(strings.NewReader(tt.zone), tt.origin)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseZoneFile(%q) returned %v, want %q", tt.zone, err, tt.want)
+		}
+	}
+}